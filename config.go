@@ -5,6 +5,7 @@ import (
 	"io"
 	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/hairyhenderson/gomplate/v5/internal/config"
 	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+	"github.com/hairyhenderson/gomplate/v5/internal/urlhelpers"
 	"github.com/hairyhenderson/yaml"
 )
 
@@ -40,8 +42,8 @@ type Config struct {
 	// Usually this should be left as default - this will be set at runtime.
 	Stdout io.Writer `yaml:"-"`
 
-	// Stderr - override for plugins to write to stderr. Can't be set in the
-	// config file.
+	// Stderr - override for plugins and exec datasources to write to stderr.
+	// Can't be set in the config file.
 	// Usually this should be left as default - this will be set at runtime.
 	Stderr io.Writer `yaml:"-"`
 
@@ -55,6 +57,11 @@ type Config struct {
 	Templates   map[string]DataSource   `yaml:"templates,omitempty"`
 	Plugins     map[string]PluginConfig `yaml:"plugins,omitempty"`
 
+	// DatasourceOverrides - replacement URLs for datasources, by alias. Only
+	// the URL is replaced, so headers and other settings are kept. Applies
+	// also to datasources defined in templates.
+	DatasourceOverrides map[string]string `yaml:"datasourceOverrides,omitempty"`
+
 	Input                 string   `yaml:"in,omitempty"`
 	InputDir              string   `yaml:"inputDir,omitempty"`
 	InputFiles            []string `yaml:"inputFiles,omitempty,flow"`
@@ -75,8 +82,38 @@ type Config struct {
 
 	PluginTimeout time.Duration `yaml:"pluginTimeout,omitempty"`
 
+	// Parallelism - the maximum number of templates to render concurrently.
+	Parallelism int `yaml:"parallelism,omitempty"`
+
+	// CacheDir - the directory for the persistent datasource cache. Defaults
+	// to a "gomplate" directory in the user's cache directory.
+	CacheDir string `yaml:"cacheDir,omitempty"`
+
+	// Offline - read remote datasources only from the persistent cache, and
+	// fail if they haven't been cached.
+	Offline bool `yaml:"offline,omitempty"`
+
+	// RecordDatasources - record every datasource read into this fixture file
+	RecordDatasources string `yaml:"recordDatasources,omitempty"`
+
+	// ReplayDatasources - serve datasource reads only from this fixture file,
+	// previously written with RecordDatasources
+	ReplayDatasources string `yaml:"replayDatasources,omitempty"`
+
 	ExecPipe     bool `yaml:"execPipe,omitempty"`
 	Experimental bool `yaml:"experimental,omitempty"`
+
+	// Check - render all templates without writing anything, and fail if any
+	// output file's content differs from what was rendered.
+	Check bool `yaml:"check,omitempty"`
+
+	// Diff - render all templates without writing anything, and print a
+	// unified diff for each output file that would be created or changed.
+	Diff bool `yaml:"diff,omitempty"`
+
+	// Watch - keep running, and re-render templates whenever input files,
+	// nested templates, or file-backed datasources change.
+	Watch bool `yaml:"watch,omitempty"`
 }
 
 // mergeDataSourceMaps - use d as defaults, and override with values from o
@@ -102,12 +139,54 @@ func mergeDataSources(left, right DataSource) DataSource {
 	} else {
 		maps.Copy(left.Header, right.Header)
 	}
+	if right.CacheTTL != 0 {
+		left.CacheTTL = right.CacheTTL
+	}
+	if right.Retries != 0 {
+		left.Retries = right.Retries
+	}
+	if right.Timeout != 0 {
+		left.Timeout = right.Timeout
+	}
+	if right.Backoff != 0 {
+		left.Backoff = right.Backoff
+	}
+	if right.TLS != nil {
+		left.TLS = right.TLS
+	}
+	if right.Method != "" {
+		left.Method = right.Method
+	}
+	if right.Body != "" {
+		left.Body = right.Body
+	}
 	return left
 }
 
 // DataSource - datasource configuration
 type DataSource = config.DataSource
 
+// TLSConfig - TLS settings for HTTPS datasources
+type TLSConfig = config.TLSConfig
+
+// parseDatasourceOverrides - parse the URLs in DatasourceOverrides
+func (c *Config) parseDatasourceOverrides() (map[string]*url.URL, error) {
+	if len(c.DatasourceOverrides) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]*url.URL, len(c.DatasourceOverrides))
+	for alias, v := range c.DatasourceOverrides {
+		u, err := urlhelpers.ParseSourceURL(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override URL for datasource %q: %w", alias, err)
+		}
+		overrides[alias] = u
+	}
+
+	return overrides, nil
+}
+
 type PluginConfig struct {
 	Cmd     string
 	Args    []string      `yaml:"args,omitempty"`
@@ -223,6 +302,30 @@ func (c *Config) MergeFrom(o *Config) *Config {
 	if !isZero(o.PluginTimeout) {
 		c.PluginTimeout = o.PluginTimeout
 	}
+	if !isZero(o.Parallelism) {
+		c.Parallelism = o.Parallelism
+	}
+	if !isZero(o.CacheDir) {
+		c.CacheDir = o.CacheDir
+	}
+	if !isZero(o.Offline) {
+		c.Offline = o.Offline
+	}
+	if !isZero(o.RecordDatasources) {
+		c.RecordDatasources = o.RecordDatasources
+	}
+	if !isZero(o.ReplayDatasources) {
+		c.ReplayDatasources = o.ReplayDatasources
+	}
+	if !isZero(o.Check) {
+		c.Check = o.Check
+	}
+	if !isZero(o.Diff) {
+		c.Diff = o.Diff
+	}
+	if !isZero(o.Watch) {
+		c.Watch = o.Watch
+	}
 	if c.Templates == nil {
 		c.Templates = o.Templates
 	} else {
@@ -244,6 +347,12 @@ func (c *Config) MergeFrom(o *Config) *Config {
 		}
 		maps.Copy(c.Plugins, o.Plugins)
 	}
+	if len(o.DatasourceOverrides) > 0 {
+		if c.DatasourceOverrides == nil {
+			c.DatasourceOverrides = map[string]string{}
+		}
+		maps.Copy(c.DatasourceOverrides, o.DatasourceOverrides)
+	}
 	if len(o.ExtraHeaders) > 0 {
 		if c.ExtraHeaders == nil {
 			c.ExtraHeaders = map[string]http.Header{}
@@ -304,6 +413,38 @@ func (c Config) validate() (err error) {
 		}
 	}
 
+	if err == nil {
+		err = notTogether(
+			[]string{"check", "watch", "execPipe"},
+			c.Check, c.Watch, c.ExecPipe)
+	}
+
+	if err == nil {
+		err = notTogether(
+			[]string{"diff", "watch", "execPipe"},
+			c.Diff, c.Watch, c.ExecPipe)
+	}
+
+	if err == nil {
+		err = notTogether(
+			[]string{"recordDatasources", "replayDatasources"},
+			c.RecordDatasources, c.ReplayDatasources)
+	}
+
+	if err == nil {
+		err = notTogether(
+			[]string{"recordDatasources", "watch"},
+			c.RecordDatasources, c.Watch)
+	}
+
+	if err == nil {
+		err = c.validateWatch()
+	}
+
+	if err == nil && c.Parallelism < 0 {
+		err = fmt.Errorf("parallelism must not be negative (was %d)", c.Parallelism)
+	}
+
 	if err == nil {
 		missingKeyValues := []string{"", "error", "zero", "default", "invalid"}
 		if !slices.Contains(missingKeyValues, c.MissingKey) {
@@ -314,6 +455,24 @@ func (c Config) validate() (err error) {
 	return err
 }
 
+// validateWatch - watch mode needs inputs that can be re-read, and never
+// returns, so can't be followed by a post-exec command
+func (c Config) validateWatch() error {
+	if !c.Watch {
+		return nil
+	}
+
+	if slices.Contains(c.InputFiles, "-") {
+		return fmt.Errorf("watch can not be used when reading templates from stdin")
+	}
+
+	if len(c.PostExec) > 0 {
+		return fmt.Errorf("watch can not be used with postExec")
+	}
+
+	return nil
+}
+
 func notTogether(names []string, values ...any) error {
 	found := ""
 	for i, value := range values {
@@ -346,6 +505,8 @@ func isZero(value any) bool {
 		return len(v) == 0
 	case bool:
 		return !v
+	case int:
+		return v == 0
 	case time.Duration:
 		return v == 0
 	default:
@@ -388,6 +549,10 @@ func (c *Config) applyDefaults() {
 	if c.PluginTimeout == 0 {
 		c.PluginTimeout = 5 * time.Second
 	}
+
+	if c.Parallelism == 0 {
+		c.Parallelism = 1
+	}
 }
 
 // getMode - parse an os.FileMode out of the string, and let us know if it's an override or not...
@@ -35,6 +35,20 @@ datasources:
     url: https://example.com/more.json
     header:
       Authorization: ["Bearer abcd1234"]
+    cacheTTL: 5m
+    retries: 3
+    timeout: 10s
+    backoff: 500ms
+    tls:
+      certFile: client.crt
+      keyFile: client.key
+      caFile: ca.pem
+      serverName: example.com
+    method: POST
+    body: '{"query": "{ hello }"}'
+
+datasourceOverrides:
+  moredata: ./more.json
 
 context:
   .:
@@ -63,8 +77,21 @@ pluginTimeout: 2s
 				Header: map[string][]string{
 					"Authorization": {"Bearer abcd1234"},
 				},
+				CacheTTL: 5 * time.Minute,
+				Retries:  3,
+				Timeout:  10 * time.Second,
+				Backoff:  500 * time.Millisecond,
+				TLS: &TLSConfig{
+					CertFile:   "client.crt",
+					KeyFile:    "client.key",
+					CAFile:     "ca.pem",
+					ServerName: "example.com",
+				},
+				Method: "POST",
+				Body:   `{"query": "{ hello }"}`,
 			},
 		},
+		DatasourceOverrides: map[string]string{"moredata": "./more.json"},
 		Context: map[string]DataSource{
 			".": {
 				URL: mustURL("file:///data.json"),
@@ -150,6 +177,64 @@ postExec: [echo, foo]
 	require.Error(t, validateConfig(`execPipe: true
 outputFiles: [foo]
 postExec: [echo]
+`))
+
+	require.Error(t, validateConfig(`watch: true
+inputFiles: ['-']
+outputFiles: [out]
+`))
+
+	require.Error(t, validateConfig(`watch: true
+in: foo
+postExec: [echo]
+outputFiles: [out]
+`))
+
+	require.NoError(t, validateConfig(`watch: true
+inputDir: in
+outputDir: out
+`))
+
+	require.Error(t, validateConfig(`check: true
+watch: true
+inputDir: in
+outputDir: out
+`))
+
+	require.Error(t, validateConfig(`check: true
+execPipe: true
+postExec: [echo]
+`))
+
+	require.Error(t, validateConfig(`diff: true
+watch: true
+inputDir: in
+outputDir: out
+`))
+
+	require.Error(t, validateConfig(`parallelism: -1
+`))
+
+	require.Error(t, validateConfig(`recordDatasources: fixture.json
+replayDatasources: fixture.json
+`))
+
+	require.Error(t, validateConfig(`recordDatasources: fixture.json
+watch: true
+inputDir: in
+outputDir: out
+`))
+
+	require.NoError(t, validateConfig(`replayDatasources: fixture.json
+watch: true
+inputDir: in
+outputDir: out
+`))
+
+	require.NoError(t, validateConfig(`diff: true
+check: true
+inputDir: in
+outputDir: out
 `))
 
 	require.NoError(t, validateConfig(`execPipe: true
@@ -213,6 +298,7 @@ func TestMergeFrom(t *testing.T) {
 					"Accept": {"foo/bar"},
 				},
 			},
+			"moredata": {CacheTTL: time.Minute, Retries: 2},
 		},
 		Context: map[string]DataSource{
 			"foo": {
@@ -239,6 +325,8 @@ func TestMergeFrom(t *testing.T) {
 				Header: http.Header{
 					"Authorization": {"Bearer abcd1234"},
 				},
+				CacheTTL: time.Minute,
+				Retries:  2,
 			},
 		},
 		Context: map[string]DataSource{
@@ -442,6 +530,7 @@ func TestMergeFromCoversAllFields(t *testing.T) {
 		Context:               map[string]DataSource{"sample": {URL: mustURL("stdin:///")}},
 		Templates:             map[string]DataSource{"sample": {URL: mustURL("stdin:///")}},
 		Plugins:               map[string]PluginConfig{"sample": {Cmd: "echo"}},
+		DatasourceOverrides:   map[string]string{"sample": "stdin:///"},
 		ExtraHeaders:          map[string]http.Header{"sample": {"Accept": {"application/json"}}},
 		Input:                 "sample",
 		InputDir:              "sample",
@@ -457,8 +546,16 @@ func TestMergeFromCoversAllFields(t *testing.T) {
 		MissingKey:            "sample",
 		PostExec:              []string{"sample"},
 		PluginTimeout:         time.Second,
+		Parallelism:           4,
+		CacheDir:              "/tmp/cache",
+		Offline:               true,
+		RecordDatasources:     "fixture.json",
+		ReplayDatasources:     "fixture.json",
 		ExecPipe:              true,
 		Experimental:          true,
+		Check:                 true,
+		Diff:                  true,
+		Watch:                 true,
 	}
 	cfgVal := reflect.ValueOf(cfg)
 
@@ -491,6 +588,7 @@ leftDelim: '{{'
 rightDelim: '}}'
 missingKey: error
 pluginTimeout: 5s
+parallelism: 1
 `
 		assert.Equal(t, expected, c.String())
 	})
@@ -617,6 +715,7 @@ func TestApplyDefaults(t *testing.T) {
 	assert.Empty(t, cfg.OutputDir)
 	assert.Equal(t, "{{", cfg.LDelim)
 	assert.Equal(t, "}}", cfg.RDelim)
+	assert.Equal(t, 1, cfg.Parallelism)
 
 	cfg = &Config{
 		InputDir: "in",
@@ -28,7 +28,7 @@ func createTmplContext(
 			return nil, err
 		}
 
-		content, err := parsers.ParseData(ct, string(b))
+		content, err := parsers.RegistryFromContext(ctx).ParseData(ct, string(b))
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,522 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/hairyhenderson/gomplate/v5/internal/urlhelpers"
+)
+
+// TemplateDependencies - the inputs referenced by a template, as found by
+// parsing it without rendering.
+//
+// Experimental: subject to breaking changes before the next major release
+type TemplateDependencies struct {
+	// Template - the input template file, or "<arg>" for templates given with
+	// the 'in' option
+	Template string `json:"template"`
+	// Output - the output file ("-" for standard output)
+	Output string `json:"output"`
+
+	// Datasources - aliases of the datasources read by the template
+	Datasources []string `json:"datasources,omitempty"`
+	// Context - aliases of the datasources in the template's context
+	Context []string `json:"context,omitempty"`
+	// Includes - aliases of the datasources included by the template
+	Includes []string `json:"includes,omitempty"`
+	// Templates - names of the nested templates referenced by the template
+	Templates []string `json:"templates,omitempty"`
+	// Files - paths of the files read with file.Read
+	Files []string `json:"files,omitempty"`
+	// Plugins - names of the plugins called by the template
+	Plugins []string `json:"plugins,omitempty"`
+
+	// Prerequisites - all local files the output depends on, including the
+	// template itself
+	Prerequisites []string `json:"prerequisites"`
+
+	// Incomplete - set when some references can't be determined without
+	// rendering, because their arguments aren't literal strings
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// Deps parses all templates specified by the given configuration, and returns
+// the inputs each references. Templates are not rendered, and nothing is
+// written.
+//
+// Experimental: subject to breaking changes before the next major release
+func Deps(ctx context.Context, cfg *Config) ([]TemplateDependencies, error) {
+	a, tmpls, outputs, err := newDepsAnalyzer(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]TemplateDependencies, 0, len(outputs))
+	for _, t := range tmpls {
+		d, err := a.analyze(t.Name, t.Text)
+		if err != nil {
+			return nil, err
+		}
+
+		// several templates may be written to standard output, but only one
+		// can be recorded as its source
+		d.Output = "-"
+		if out, ok := outputs[t.Name]; ok {
+			d.Output = out
+			delete(outputs, t.Name)
+		}
+
+		deps = append(deps, d)
+	}
+
+	// the remaining outputs are copied without being parsed
+	for in, out := range outputs {
+		deps = append(deps, TemplateDependencies{
+			Template:      in,
+			Output:        out,
+			Prerequisites: []string{in},
+		})
+	}
+
+	slices.SortFunc(deps, func(a, b TemplateDependencies) int {
+		return strings.Compare(a.Output, b.Output)
+	})
+
+	return deps, nil
+}
+
+// depsAnalyzer finds the dependencies of templates by walking their parse trees
+type depsAnalyzer struct {
+	ctx       context.Context
+	cfg       *Config
+	overrides map[string]*url.URL
+}
+
+// newDepsAnalyzer prepares to analyze the templates specified by cfg, and
+// gathers them without writing anything. The returned map holds the output
+// file for each input file.
+func newDepsAnalyzer(ctx context.Context, cfg *Config) (*depsAnalyzer, []Template, map[string]string, error) {
+	cfg.applyDefaults()
+
+	err := cfg.validate()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to validate config: %w\n%+v", err, cfg)
+	}
+
+	ctx = datafs.ContextWithStdin(ctx, cfg.Stdin)
+	if datafs.FSProviderFromContext(ctx) == nil {
+		ctx = datafs.ContextWithFSProvider(ctx, DefaultFSProvider)
+	}
+
+	overrides, err := cfg.parseDatasourceOverrides()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// gathering templates while collecting a drift report writes nothing, and
+	// records the input for each output
+	drift := &driftReport{}
+	ctx = contextWithDriftReport(ctx, drift)
+
+	opts := optionsFromConfig(cfg)
+	opts.DatasourceOverrides = overrides
+	tr := newRenderer(opts)
+
+	tmpls, err := gatherTemplates(ctx, cfg, chooseNamer(cfg, tr))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to gather templates: %w", err)
+	}
+
+	outputs := map[string]string{}
+	for out, in := range drift.sources {
+		outputs[in] = out
+	}
+
+	return &depsAnalyzer{ctx: ctx, cfg: cfg, overrides: overrides}, tmpls, outputs, nil
+}
+
+// depsSet accumulates the references found in a template and the nested
+// templates it references
+type depsSet struct {
+	datasources map[string]struct{}
+	includes    map[string]struct{}
+	templates   map[string]struct{}
+	files       map[string]struct{}
+	plugins     map[string]struct{}
+	// datasources defined in the template with defineDatasource
+	defined    map[string]string
+	incomplete bool
+}
+
+func (a *depsAnalyzer) analyze(name, text string) (TemplateDependencies, error) {
+	s := &depsSet{
+		datasources: map[string]struct{}{},
+		includes:    map[string]struct{}{},
+		templates:   map[string]struct{}{},
+		files:       map[string]struct{}{},
+		plugins:     map[string]struct{}{},
+		defined:     map[string]string{},
+	}
+
+	err := a.parse(s, name, text)
+	if err != nil {
+		return TemplateDependencies{}, err
+	}
+
+	// nested templates may reference other nested templates, so keep going
+	// until there's nothing new
+	parsed := map[string]bool{}
+	for {
+		pending := []string{}
+		for t := range s.templates {
+			if !parsed[t] {
+				pending = append(pending, t)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		slices.Sort(pending)
+		for _, t := range pending {
+			parsed[t] = true
+
+			err = a.parseNested(s, t)
+			if err != nil {
+				return TemplateDependencies{}, err
+			}
+		}
+	}
+
+	d := TemplateDependencies{
+		Template:    name,
+		Datasources: sortedKeys(s.datasources),
+		Context:     sortedKeys(a.cfg.Context),
+		Includes:    sortedKeys(s.includes),
+		Templates:   sortedKeys(s.templates),
+		Files:       sortedKeys(s.files),
+		Plugins:     sortedKeys(s.plugins),
+		Incomplete:  s.incomplete,
+	}
+
+	d.Prerequisites = a.prerequisites(name, s)
+
+	return d, nil
+}
+
+// parse parses the template text, and records all references found in it
+func (a *depsAnalyzer) parse(s *depsSet, name, text string) error {
+	// functions aren't needed, since nothing is executed
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+
+	trees := map[string]*parse.Tree{}
+	_, err := t.Parse(text, a.cfg.LDelim, a.cfg.RDelim, trees)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	for _, tree := range trees {
+		a.walk(s, trees, tree.Root, nil)
+	}
+
+	return nil
+}
+
+// parseNested parses the nested template with the given name, if it can be
+// read from a local file
+func (a *depsAnalyzer) parseNested(s *depsSet, name string) error {
+	p, ok := a.nestedPath(name)
+	if !ok {
+		return nil
+	}
+
+	fsys, err := datafs.FSysForPath(a.ctx, p)
+	if err != nil {
+		return fmt.Errorf("fsysForPath: %w", err)
+	}
+
+	b, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		// directories and missing files are reported as prerequisites, but
+		// can't be parsed
+		return nil
+	}
+
+	return a.parse(s, name, string(b))
+}
+
+// nestedPath returns the local path for the named nested template, if it's
+// defined in a local file or directory
+func (a *depsAnalyzer) nestedPath(name string) (string, bool) {
+	for alias, ds := range a.cfg.Templates {
+		p, ok := localPath(ds.URL)
+		if !ok {
+			continue
+		}
+
+		if name == alias {
+			return p, true
+		}
+
+		if rest, found := strings.CutPrefix(name, alias+"/"); found {
+			return filepath.Join(p, filepath.FromSlash(rest)), true
+		}
+	}
+
+	return "", false
+}
+
+// walk records the references in node and all of its children. The piped
+// argument is the literal string piped into a command, if any.
+func (a *depsAnalyzer) walk(s *depsSet, trees map[string]*parse.Tree, node parse.Node, piped parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			a.walk(s, trees, c, nil)
+		}
+	case *parse.ActionNode:
+		a.walk(s, trees, n.Pipe, nil)
+	case *parse.IfNode:
+		a.walkBranch(s, trees, &n.BranchNode)
+	case *parse.RangeNode:
+		a.walkBranch(s, trees, &n.BranchNode)
+	case *parse.WithNode:
+		a.walkBranch(s, trees, &n.BranchNode)
+	case *parse.TemplateNode:
+		// templates defined in the same file aren't external references
+		if _, ok := trees[n.Name]; !ok {
+			s.templates[n.Name] = struct{}{}
+		}
+		a.walk(s, trees, n.Pipe, nil)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		var prev parse.Node
+		for _, c := range n.Cmds {
+			a.walk(s, trees, c, prev)
+
+			// only a literal string piped into the next command can be
+			// resolved
+			prev = nil
+			if len(c.Args) == 1 && c.Args[0].Type() == parse.NodeString {
+				prev = c.Args[0]
+			}
+		}
+	case *parse.CommandNode:
+		a.command(s, trees, n, piped)
+		for _, arg := range n.Args {
+			a.walk(s, trees, arg, nil)
+		}
+	case *parse.ChainNode:
+		a.walk(s, trees, n.Node, nil)
+	}
+}
+
+func (a *depsAnalyzer) walkBranch(s *depsSet, trees map[string]*parse.Tree, n *parse.BranchNode) {
+	a.walk(s, trees, n.Pipe, nil)
+	a.walk(s, trees, n.List, nil)
+	a.walk(s, trees, n.ElseList, nil)
+}
+
+// command records the reference made by the command, if it calls one of the
+// functions that read inputs
+func (a *depsAnalyzer) command(s *depsSet, trees map[string]*parse.Tree, cmd *parse.CommandNode, piped parse.Node) {
+	fn := funcName(cmd.Args[0])
+	if fn == "" {
+		return
+	}
+
+	args := slices.Clone(cmd.Args[1:])
+	if piped != nil {
+		args = append(args, piped)
+	}
+
+	// arg returns the literal string argument at index i, and marks the
+	// result incomplete if it's not a literal
+	arg := func(i int) (string, bool) {
+		if i < len(args) {
+			if sn, ok := args[i].(*parse.StringNode); ok {
+				return sn.Text, true
+			}
+		}
+		s.incomplete = true
+		return "", false
+	}
+
+	switch fn {
+	case "datasource", "ds", "datasourceExists", "datasourceReachable":
+		if alias, ok := arg(0); ok {
+			s.datasources[alias] = struct{}{}
+		}
+	case "defineDatasource":
+		alias, ok := arg(0)
+		if !ok {
+			return
+		}
+		if u, ok := arg(1); ok {
+			s.defined[alias] = u
+		}
+	case "include":
+		if alias, ok := arg(0); ok {
+			s.includes[alias] = struct{}{}
+		}
+	case "file.Read":
+		if p, ok := arg(0); ok {
+			s.files[p] = struct{}{}
+		}
+	case "tmpl.Exec":
+		// as with the template action, templates defined in the same file
+		// aren't external references
+		if name, ok := arg(0); ok {
+			if _, ok := trees[name]; !ok {
+				s.templates[name] = struct{}{}
+			}
+		}
+	default:
+		if _, ok := a.cfg.Plugins[fn]; ok {
+			s.plugins[fn] = struct{}{}
+		}
+	}
+}
+
+// funcName returns the name of the function called by a command, e.g. "ds" or
+// "file.Read", or "" if it's not a function call
+func funcName(node parse.Node) string {
+	switch n := node.(type) {
+	case *parse.IdentifierNode:
+		return n.Ident
+	case *parse.ChainNode:
+		if id, ok := n.Node.(*parse.IdentifierNode); ok {
+			return id.Ident + "." + strings.Join(n.Field, ".")
+		}
+	}
+
+	return ""
+}
+
+// prerequisites returns all local files that the template's output depends on
+func (a *depsAnalyzer) prerequisites(name string, s *depsSet) []string {
+	prereqs := map[string]struct{}{}
+	if name != "<arg>" {
+		prereqs[name] = struct{}{}
+	}
+
+	aliases := slices.Concat(sortedKeys(s.datasources), sortedKeys(s.includes), sortedKeys(a.cfg.Context))
+	for _, alias := range aliases {
+		for _, p := range a.datasourcePaths(s, alias, false, map[string]bool{}) {
+			prereqs[p] = struct{}{}
+		}
+	}
+
+	for t := range s.templates {
+		if p, ok := a.nestedPath(t); ok {
+			prereqs[p] = struct{}{}
+		}
+	}
+
+	for f := range s.files {
+		prereqs[filepath.Clean(f)] = struct{}{}
+	}
+
+	for p := range s.plugins {
+		// only plugins given as paths are local files - others are looked up
+		// in the PATH
+		if cmd := a.cfg.Plugins[p].Cmd; filepath.Base(cmd) != cmd {
+			prereqs[cmd] = struct{}{}
+		}
+	}
+
+	return sortedKeys(prereqs)
+}
+
+// datasourcePaths returns the local paths read by the datasource with the
+// given alias. Merged datasources are expanded to their parts.
+func (a *depsAnalyzer) datasourcePaths(s *depsSet, alias string, mergePart bool, seen map[string]bool) []string {
+	if seen[alias] {
+		return nil
+	}
+	seen[alias] = true
+
+	u := a.datasourceURL(s, alias, mergePart)
+	if u == nil {
+		return nil
+	}
+
+	if u.Scheme == "merge" {
+		paths := []string{}
+		for part := range strings.SplitSeq(u.Opaque, "|") {
+			paths = append(paths, a.datasourcePaths(s, part, true, seen)...)
+		}
+		return paths
+	}
+
+	if p, ok := localPath(u); ok {
+		return []string{p}
+	}
+
+	return nil
+}
+
+// datasourceURL resolves the alias to a URL the same way datasources are
+// resolved when rendering - undefined aliases are treated as URLs, which must
+// be absolute unless they're parts of a merged datasource.
+func (a *depsAnalyzer) datasourceURL(s *depsSet, alias string, mergePart bool) *url.URL {
+	if u, ok := a.overrides[alias]; ok {
+		return u
+	}
+	if ds, ok := a.cfg.DataSources[alias]; ok {
+		return ds.URL
+	}
+	if ds, ok := a.cfg.Context[alias]; ok {
+		return ds.URL
+	}
+
+	if raw, ok := s.defined[alias]; ok {
+		u, err := urlhelpers.ParseSourceURL(raw)
+		if err != nil {
+			return nil
+		}
+		return u
+	}
+
+	if mergePart {
+		u, err := urlhelpers.ParseSourceURL(alias)
+		if err != nil {
+			return nil
+		}
+		return u
+	}
+
+	u, err := url.Parse(alias)
+	if err != nil || !u.IsAbs() {
+		return nil
+	}
+
+	return u
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
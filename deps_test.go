@@ -0,0 +1,147 @@
+package gomplate
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeps(t *testing.T) {
+	// chdir to root so we can use relative paths
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys, _ := mem.NewFS()
+
+	_ = hackpadfs.MkdirAll(fsys, "in", 0o777)
+	_ = hackpadfs.MkdirAll(fsys, "tpl", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "in/a.tmpl", []byte(`{{ (ds "config").name }}
+{{ "inc" | include }}
+{{ template "tpl/nested.tmpl" . }}
+{{ define "local" }}local{{ end }}{{ template "local" }}
+{{ file.Read "extra.txt" }}
+{{ shout "hi" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/b.tmpl", []byte(`{{ ds "merged" }}{{ ds .dynamic }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/c.txt", []byte(`copied`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "tpl/nested.tmpl", []byte(`{{ datasource "nested" }}`), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	cfg := &Config{
+		InputDir:              "in",
+		OutputDir:             "out",
+		ExcludeProcessingGlob: []string{"*.txt"},
+		DataSources: map[string]DataSource{
+			"config": {URL: mustURL("config.json")},
+			"inc":    {URL: mustURL("https://example.com/inc")},
+			"nested": {URL: mustURL("nested.yaml")},
+			"merged": {URL: mustURL("merge:config|over.json")},
+		},
+		Templates: map[string]DataSource{
+			"tpl": {URL: mustURL("tpl/")},
+		},
+		Plugins: map[string]PluginConfig{
+			"shout": {Cmd: "/bin/shout"},
+		},
+	}
+
+	deps, err := Deps(ctx, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TemplateDependencies{
+		{
+			Template:      "in/a.tmpl",
+			Output:        "out/a.tmpl",
+			Datasources:   []string{"config", "nested"},
+			Includes:      []string{"inc"},
+			Templates:     []string{"tpl/nested.tmpl"},
+			Files:         []string{"extra.txt"},
+			Plugins:       []string{"shout"},
+			Prerequisites: []string{"/bin/shout", "config.json", "extra.txt", "in/a.tmpl", "nested.yaml", "tpl/nested.tmpl"},
+		},
+		{
+			Template:      "in/b.tmpl",
+			Output:        "out/b.tmpl",
+			Datasources:   []string{"merged"},
+			Prerequisites: []string{"config.json", "in/b.tmpl", "over.json"},
+			Incomplete:    true,
+		},
+		{
+			Template:      "in/c.txt",
+			Output:        "out/c.txt",
+			Prerequisites: []string{"in/c.txt"},
+		},
+	}, deps)
+
+	// nothing was written
+	_, err = hackpadfs.Stat(fsys, "out")
+	require.Error(t, err)
+}
+
+func TestDeps_Overrides(t *testing.T) {
+	fsys, _ := mem.NewFS()
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	cfg := &Config{
+		Input: `{{ ds "foo" }}{{ defineDatasource "bar" "bar.json" }}{{ ds "bar" }}`,
+		DataSources: map[string]DataSource{
+			"foo": {URL: mustURL("https://example.com/foo")},
+		},
+		DatasourceOverrides: map[string]string{"foo": "foo.json"},
+	}
+
+	deps, err := Deps(ctx, cfg)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+
+	assert.Equal(t, "-", deps[0].Output)
+	assert.Equal(t, []string{"bar", "foo"}, deps[0].Datasources)
+	assert.Equal(t, []string{"bar.json", "foo.json"}, deps[0].Prerequisites)
+	assert.False(t, deps[0].Incomplete)
+}
+
+func TestDeps_TmplExec(t *testing.T) {
+	fsys, _ := mem.NewFS()
+	_ = hackpadfs.MkdirAll(fsys, "tpl", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "tpl/exec.tmpl", []byte(`{{ ds "nested" }}`), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	cfg := &Config{
+		Input: `{{ tmpl.Exec "tpl/exec.tmpl" . }}{{ define "local" }}local{{ end }}{{ tmpl.Exec "local" }}`,
+		DataSources: map[string]DataSource{
+			"nested": {URL: mustURL("nested.json")},
+		},
+		Templates: map[string]DataSource{
+			"tpl": {URL: mustURL("tpl/")},
+		},
+	}
+
+	deps, err := Deps(ctx, cfg)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+
+	assert.Equal(t, []string{"tpl/exec.tmpl"}, deps[0].Templates)
+	assert.Equal(t, []string{"nested"}, deps[0].Datasources)
+	assert.Equal(t, []string{"nested.json", "tpl/exec.tmpl"}, deps[0].Prerequisites)
+	assert.False(t, deps[0].Incomplete)
+
+	// a template name that isn't a literal can't be resolved
+	cfg.Input = `{{ tmpl.Exec .name }}`
+
+	deps, err = Deps(ctx, cfg)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+
+	assert.Empty(t, deps[0].Templates)
+	assert.True(t, deps[0].Incomplete)
+}
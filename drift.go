@@ -0,0 +1,206 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// driftReport collects the output files whose rendered content differs from
+// what's currently on disk. Used in check and diff modes, where nothing is
+// written.
+type driftReport struct {
+	// the input template for each output file, for labelling
+	sources map[string]string
+	files   []driftedFile
+	mu      sync.Mutex
+}
+
+// driftedFile is an output file whose rendered content differs from what's on
+// disk
+type driftedFile struct {
+	path     string
+	current  []byte
+	rendered []byte
+	// whether the file exists yet
+	exists bool
+}
+
+type driftCtxKey struct{}
+
+func contextWithDriftReport(ctx context.Context, r *driftReport) context.Context {
+	return context.WithValue(ctx, driftCtxKey{}, r)
+}
+
+func driftReportFromContext(ctx context.Context) *driftReport {
+	r, _ := ctx.Value(driftCtxKey{}).(*driftReport)
+	return r
+}
+
+// labelOutput records the input template that renders to the given output file,
+// if a drift report is being collected
+func labelOutput(ctx context.Context, outFile, inFile string) {
+	r := driftReportFromContext(ctx)
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sources == nil {
+		r.sources = map[string]string{}
+	}
+	r.sources[outFile] = inFile
+}
+
+func (r *driftReport) add(f driftedFile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.files = append(r.files, f)
+}
+
+// report writes either a unified diff (if diff is set) or a list of the drifted
+// output files to w. If check is set, an error is returned when any file has
+// drifted.
+func (r *driftReport) report(w io.Writer, diff, check bool) error {
+	r.mu.Lock()
+	files := slices.Clone(r.files)
+	r.mu.Unlock()
+
+	slices.SortFunc(files, func(a, b driftedFile) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	for _, f := range files {
+		if !diff {
+			fmt.Fprintln(w, f.path)
+			continue
+		}
+
+		err := r.writeDiff(w, f)
+		if err != nil {
+			return err
+		}
+	}
+
+	if check && len(files) > 0 {
+		return fmt.Errorf("rendered output differs from %d existing file(s)", len(files))
+	}
+
+	return nil
+}
+
+// writeDiff writes a unified diff between the current and rendered content of
+// the file. New files are diffed against /dev/null.
+func (r *driftReport) writeDiff(w io.Writer, f driftedFile) error {
+	r.mu.Lock()
+	source := r.sources[f.path]
+	r.mu.Unlock()
+
+	from := f.path
+	if !f.exists {
+		from = "/dev/null"
+	}
+
+	to := f.path
+	if source != "" {
+		to += " (rendered from " + source + ")"
+	}
+
+	ud := difflib.UnifiedDiff{
+		A:        splitLines(f.current),
+		B:        splitLines(f.rendered),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	}
+
+	err := difflib.WriteUnifiedDiff(w, ud)
+	if err != nil {
+		return fmt.Errorf("failed to write diff for %s: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// splitLines splits b into lines for diffing, each ending in a newline
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return lines
+}
+
+// writer returns a writer that compares everything written to it with the
+// current content of filename when closed, without modifying the file. As with
+// regular output, whitespace-only output is not considered a change.
+func (r *driftReport) writer(ctx context.Context, filename string) io.Writer {
+	// there's nothing to compare standard output with
+	if filename == "-" {
+		return io.Discard
+	}
+
+	return iohelpers.NewEmptySkipper(func() (io.Writer, error) {
+		return &driftWriter{ctx: ctx, filename: filename, report: r}, nil
+	})
+}
+
+type driftWriter struct {
+	ctx      context.Context
+	report   *driftReport
+	filename string
+	buf      bytes.Buffer
+}
+
+var _ io.WriteCloser = (*driftWriter)(nil)
+
+func (w *driftWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Close - compares the buffered output with the current file content
+func (w *driftWriter) Close() error {
+	f := driftedFile{path: w.filename, rendered: w.buf.Bytes()}
+
+	fsys, err := datafs.FSysForPath(w.ctx, w.filename)
+	if err != nil {
+		w.report.add(f)
+		return fmt.Errorf("fsysForPath: %w", err)
+	}
+
+	f.current, err = fs.ReadFile(fsys, w.filename)
+	if err != nil {
+		w.report.add(f)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("readFile %q: %w", w.filename, err)
+	}
+	f.exists = true
+
+	if !bytes.Equal(f.current, f.rendered) {
+		w.report.add(f)
+	}
+
+	return nil
+}
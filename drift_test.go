@@ -0,0 +1,128 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCheck(t *testing.T) {
+	// chdir to root so we can use relative paths
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys, _ := mem.NewFS()
+
+	_ = hackpadfs.MkdirAll(fsys, "in", 0o777)
+	_ = hackpadfs.MkdirAll(fsys, "out", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "in/same", []byte(`{{ "same" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/changed", []byte(`{{ "new" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/missing", []byte(`{{ "missing" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/empty", []byte(`   `), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "out/same", []byte("same"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "out/changed", []byte("old"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		InputDir:  "in",
+		OutputDir: "out",
+		Check:     true,
+		Stdout:    stdout,
+	}
+
+	err := Run(ctx, cfg)
+	require.Error(t, err)
+	assert.Equal(t, "out/changed\nout/missing\n", stdout.String())
+
+	// nothing was written
+	b, err := hackpadfs.ReadFile(fsys, "out/changed")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(b))
+
+	_, err = hackpadfs.Stat(fsys, "out/missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// no drift once everything's up to date
+	_ = hackpadfs.WriteFullFile(fsys, "out/changed", []byte("new"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "out/missing", []byte("missing"), 0o644)
+
+	stdout.Reset()
+	err = Run(ctx, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunDiff(t *testing.T) {
+	// chdir to root so we can use relative paths
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys, _ := mem.NewFS()
+
+	_ = hackpadfs.MkdirAll(fsys, "in", 0o777)
+	_ = hackpadfs.MkdirAll(fsys, "out", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "in/changed", []byte("one\n{{ \"two\" | toUpper }}\nthree\n"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/new", []byte("hello\nworld\n"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/same", []byte("same\n"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "out/changed", []byte("one\ntwo\nthree\n"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "out/same", []byte("same\n"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		InputDir:  "in",
+		OutputDir: "out",
+		Diff:      true,
+		Stdout:    stdout,
+	}
+
+	err := Run(ctx, cfg)
+	require.NoError(t, err)
+
+	expected := `--- out/changed
++++ out/changed (rendered from in/changed)
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+--- /dev/null
++++ out/new (rendered from in/new)
+@@ -0,0 +1,2 @@
++hello
++world
+`
+	assert.Equal(t, expected, stdout.String())
+
+	// nothing was written
+	b, err := hackpadfs.ReadFile(fsys, "out/changed")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(b))
+
+	_, err = hackpadfs.Stat(fsys, "out/new")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// with check, the diff is printed and an error returned
+	stdout.Reset()
+	cfg.Check = true
+	err = Run(ctx, cfg)
+	require.Error(t, err)
+	assert.Equal(t, expected, stdout.String())
+}
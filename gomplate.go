@@ -54,6 +54,30 @@ func Run(ctx context.Context, cfg *Config) error {
 	// extract the rendering options from the config
 	opts := optionsFromConfig(cfg)
 	opts.Funcs = funcMap
+
+	opts.DatasourceOverrides, err = cfg.parseDatasourceOverrides()
+	if err != nil {
+		return err
+	}
+
+	opts.fixture, opts.replay, err = datasourceFixture(cfg)
+	if err != nil {
+		return err
+	}
+
+	// in watch mode, templates are gathered and rendered by the watcher
+	if cfg.Watch {
+		return watch(ctx, cfg, opts)
+	}
+
+	// in check and diff modes, output is compared with existing files instead
+	// of being written
+	var drift *driftReport
+	if cfg.Check || cfg.Diff {
+		drift = &driftReport{}
+		ctx = contextWithDriftReport(ctx, drift)
+	}
+
 	tr := newRenderer(opts)
 
 	start := time.Now()
@@ -76,9 +100,34 @@ func Run(ctx context.Context, cfg *Config) error {
 		return err
 	}
 
+	if cfg.RecordDatasources != "" {
+		err = opts.fixture.Save(cfg.RecordDatasources)
+		if err != nil {
+			return err
+		}
+	}
+
+	if drift != nil {
+		return drift.report(cfg.Stdout, cfg.Diff, cfg.Check)
+	}
+
 	return nil
 }
 
+// datasourceFixture returns the fixture to record datasource reads into, or to
+// replay them from, if either is configured
+func datasourceFixture(cfg *Config) (*datafs.Fixture, bool, error) {
+	switch {
+	case cfg.RecordDatasources != "":
+		return datafs.NewFixture(), false, nil
+	case cfg.ReplayDatasources != "":
+		f, err := datafs.LoadFixture(cfg.ReplayDatasources)
+		return f, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
 type outputNamer interface {
 	// Name the output file for the given input path
 	Name(ctx context.Context, inPath string) (string, error)
@@ -106,7 +155,7 @@ func simpleNamer(outDir string) outputNamer {
 
 func mappingNamer(outMap string, tr *renderer) outputNamer {
 	return outputNamerFunc(func(ctx context.Context, inPath string) (string, error) {
-		tcontext, err := createTmplContext(ctx, tr.tctxAliases, tr.sr)
+		tcontext, err := createTmplContext(tr.withOptions(ctx), tr.tctxAliases, tr.sr)
 		if err != nil {
 			return "", err
 		}
@@ -3,10 +3,16 @@ package gomplate
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/hairyhenderson/gomplate/v5/conv"
 	"github.com/hairyhenderson/gomplate/v5/env"
@@ -195,3 +201,137 @@ func TestMappingNamer(t *testing.T) {
 	expected = filepath.FromSlash("out/foofile")
 	assert.Equal(t, expected, out)
 }
+
+func TestRunRecordReplay(t *testing.T) {
+	srv := fakeJSONServer()
+	t.Cleanup(srv.Close)
+
+	fixture := filepath.Join(t.TempDir(), "fixture.json")
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		Input: `{{ (ds "pet").name }}`,
+		DataSources: map[string]DataSource{
+			"pet": {URL: mustURL(srv.URL)},
+		},
+		RecordDatasources: fixture,
+		Stdout:            stdout,
+	}
+	err := Run(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Luna", stdout.String())
+
+	// replaying doesn't need the server
+	srv.Close()
+
+	stdout.Reset()
+	cfg.RecordDatasources = ""
+	cfg.ReplayDatasources = fixture
+	err = Run(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Luna", stdout.String())
+
+	// reads that weren't recorded fail
+	cfg.Input = `{{ (ds "pet" "other").name }}`
+	err = Run(t.Context(), cfg)
+	require.ErrorContains(t, err, "no recorded read")
+}
+
+func TestRunPersistentCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"Luna"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		Input: `{{ (ds "pet").name }}`,
+		DataSources: map[string]DataSource{
+			"pet": {URL: mustURL(srv.URL), CacheTTL: time.Hour},
+		},
+		CacheDir: t.TempDir(),
+		Stdout:   stdout,
+	}
+
+	for range 2 {
+		stdout.Reset()
+		err := Run(t.Context(), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "Luna", stdout.String())
+	}
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRunHTTPRequestDatasource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"data": {"pet": {"name": %q}}}`, req.Variables["name"])
+	}))
+	t.Cleanup(srv.Close)
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		Input: `{{ define "query" }}{"query": "{ pet { name } }", "variables": {"name": "{{ . }}"}}{{ end -}}
+{{ (ds "api").data.pet.name }} {{ (ds "api" (tmpl.Exec "query" "Luna")).data.pet.name }}`,
+		DataSources: map[string]DataSource{
+			"api": {
+				URL:    mustURL(srv.URL + "/graphql"),
+				Method: http.MethodPost,
+				Body:   `{"query": "{ pet { name } }", "variables": {"name": "Fido"}}`,
+			},
+		},
+		Stdout: stdout,
+	}
+
+	err := Run(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Fido Luna", stdout.String())
+}
+
+func TestRunDatasourceOverrides(t *testing.T) {
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "secrets.json")
+	other := filepath.Join(dir, "other.json")
+	require.NoError(t, os.WriteFile(secrets, []byte(`{"password": "local"}`), 0o600))
+	require.NoError(t, os.WriteFile(other, []byte(`{"value": "overridden"}`), 0o600))
+
+	stdout := &bytes.Buffer{}
+	cfg := &Config{
+		Input: `{{ (ds "secrets").password }} ` +
+			`{{ defineDatasource "other" "https://example.invalid/other.json" }}{{ (ds "other").value }}`,
+		DataSources: map[string]DataSource{
+			"secrets": {
+				URL:    mustURL("vault:///secret/app"),
+				Header: http.Header{"X-Foo": {"bar"}},
+			},
+		},
+		DatasourceOverrides: map[string]string{
+			"secrets": secrets,
+			"other":   other,
+		},
+		Stdout: stdout,
+	}
+
+	err := Run(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "local overridden", stdout.String())
+
+	cfg.DatasourceOverrides = map[string]string{"secrets": "%zz"}
+	err = Run(t.Context(), cfg)
+	require.ErrorContains(t, err, "invalid override URL")
+}
@@ -171,6 +171,38 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 	if err != nil {
 		return nil, err
 	}
+	cfg.Parallelism, err = getInt(cmd, "parallelism")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Check, err = getBool(cmd, "check")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Diff, err = getBool(cmd, "diff")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Watch, err = getBool(cmd, "watch")
+	if err != nil {
+		return nil, err
+	}
+	cfg.CacheDir, err = getString(cmd, "cache-dir")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Offline, err = getBool(cmd, "offline")
+	if err != nil {
+		return nil, err
+	}
+	cfg.RecordDatasources, err = getString(cmd, "record-datasources")
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReplayDatasources, err = getString(cmd, "replay-datasources")
+	if err != nil {
+		return nil, err
+	}
 
 	cfg.LDelim, err = getString(cmd, "left-delim")
 	if err != nil {
@@ -207,6 +239,15 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 		return nil, err
 	}
 
+	overrides, err := getStringSlice(cmd, "datasource-override")
+	if err != nil {
+		return nil, err
+	}
+	cfg.DatasourceOverrides, err = parseOverrideArgs(overrides)
+	if err != nil {
+		return nil, err
+	}
+
 	pl, err := getStringSlice(cmd, "plugin")
 	if err != nil {
 		return nil, err
@@ -232,6 +273,13 @@ func getString(cmd *cobra.Command, flag string) (s string, err error) {
 	return s, err
 }
 
+func getInt(cmd *cobra.Command, flag string) (i int, err error) {
+	if cmd.Flag(flag) != nil && cmd.Flag(flag).Changed {
+		i, err = cmd.Flags().GetInt(flag)
+	}
+	return i, err
+}
+
 func getBool(cmd *cobra.Command, flag string) (b bool, err error) {
 	if cmd.Flag(flag) != nil && cmd.Flag(flag).Changed {
 		b, err = cmd.Flags().GetBool(flag)
@@ -408,6 +456,22 @@ func parseDatasourceArg(value string) (alias string, ds gomplate.DataSource, err
 	return alias, ds, err
 }
 
+func parseOverrideArgs(overrideArgs []string) (map[string]string, error) {
+	if len(overrideArgs) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]string, len(overrideArgs))
+	for _, v := range overrideArgs {
+		alias, u, ok := strings.Cut(v, "=")
+		if !ok || alias == "" || u == "" {
+			return nil, fmt.Errorf("invalid datasource-override option '%s'", v)
+		}
+		overrides[alias] = u
+	}
+	return overrides, nil
+}
+
 func parseHeaderArgs(headerArgs []string) (map[string]http.Header, error) {
 	headers := make(map[string]http.Header)
 	for _, v := range headerArgs {
@@ -526,3 +526,24 @@ func TestParseHeaderArgs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, parsed)
 }
+
+func TestParseOverrideArgs(t *testing.T) {
+	parsed, err := parseOverrideArgs(nil)
+	require.NoError(t, err)
+	assert.Nil(t, parsed)
+
+	parsed, err = parseOverrideArgs([]string{
+		"secrets=./testdata/secrets.json",
+		"api=https://localhost:8080/api?token=a=b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"secrets": "./testdata/secrets.json",
+		"api":     "https://localhost:8080/api?token=a=b",
+	}, parsed)
+
+	for _, arg := range []string{"secrets", "=foo.json", "secrets="} {
+		_, err = parseOverrideArgs([]string{arg})
+		require.Error(t, err, arg)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v5"
+	"github.com/spf13/cobra"
+)
+
+// newDepsCmd - the 'deps' subcommand, which lists the inputs referenced by
+// templates without rendering them
+func newDepsCmd(stderr io.Writer) *cobra.Command {
+	depsCmd := &cobra.Command{
+		Use:   "deps",
+		Short: "List the inputs referenced by templates, without rendering them",
+		Long: `List the datasources, included datasources, nested templates, files, and
+plugins referenced by each template, and the local files each output depends on.
+
+Templates are parsed but not rendered, so references with non-literal
+arguments can't be resolved - these templates are marked as incomplete.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			level := slog.LevelWarn
+			if v, _ := cmd.Flags().GetBool("verbose"); v {
+				level = slog.LevelDebug
+			}
+			initLogger(stderr, level)
+
+			ctx := cmd.Context()
+
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+			if format != "json" && format != "make" {
+				return fmt.Errorf("unsupported format %q, must be json or make", format)
+			}
+
+			cfg, err := loadConfig(ctx, cmd, args)
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+
+			deps, err := gomplate.Deps(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			if format == "make" {
+				return writeMakeDeps(cmd.OutOrStdout(), deps)
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(deps)
+		},
+	}
+
+	depsCmd.Flags().SortFlags = false
+	depsCmd.Flags().String("format", "json", "output `format` - json, or make for a Make-style dependency file")
+	initAnalysisFlags(depsCmd)
+
+	return depsCmd
+}
+
+// initAnalysisFlags - initialize the flags for subcommands that analyze
+// templates without rendering them. These are the subset of the main command's
+// flags that affect which templates are read, and how their references are
+// resolved.
+func initAnalysisFlags(command *cobra.Command) {
+	command.Flags().StringSliceP("datasource", "d", nil, "`datasource` in alias=URL form. Specify multiple times to add multiple sources.")
+	command.Flags().StringSlice("datasource-override", nil, "replace the URL of an already-defined datasource, in alias=URL form")
+	command.Flags().StringSliceP("context", "c", nil, "pre-load a `datasource` into the context, in alias=URL form")
+	command.Flags().StringSlice("plugin", nil, "plug in an external command as a function in name=path form")
+
+	command.Flags().StringSliceP("file", "f", []string{"-"}, "Template `file` to process. Omit to use standard input, or use --in or --input-dir")
+	command.Flags().StringP("in", "i", "", "Template `string` to process (alternative to --file and --input-dir)")
+	command.Flags().String("input-dir", "", "`directory` which is examined recursively for templates (alternative to --file and --in)")
+
+	command.Flags().StringSlice("exclude", []string{}, "glob of files to not parse")
+	command.Flags().StringSlice("exclude-processing", []string{}, "glob of files to be copied without parsing")
+	command.Flags().StringSlice("include", []string{}, "glob of files to parse")
+
+	command.Flags().StringSliceP("out", "o", []string{"-"}, "output `file` name. Omit to use standard output.")
+	command.Flags().StringSliceP("template", "t", []string{}, "Additional template file(s)")
+	command.Flags().String("output-dir", ".", "`directory` to store the processed templates. Only used for --input-dir")
+	command.Flags().String("output-map", "", "Template `string` to map the input file to an output path")
+
+	command.Flags().String("left-delim", "{{", "override the default left-`delimiter` [$GOMPLATE_LEFT_DELIM]")
+	command.Flags().String("right-delim", "}}", "override the default right-`delimiter` [$GOMPLATE_RIGHT_DELIM]")
+
+	command.Flags().BoolP("verbose", "V", false, "output extra information about what gomplate is doing")
+
+	command.Flags().String("config", defaultConfigFile, "config file (overridden by commandline flags)")
+}
+
+// writeMakeDeps writes a Make rule for each output file, with the output's
+// prerequisites. Templates written to standard output have no target, and are
+// skipped.
+func writeMakeDeps(w io.Writer, deps []gomplate.TemplateDependencies) error {
+	for _, d := range deps {
+		if d.Output == "-" {
+			continue
+		}
+
+		prereqs := make([]string, len(d.Prerequisites))
+		for i, p := range d.Prerequisites {
+			prereqs[i] = makeEscape(p)
+		}
+
+		_, err := fmt.Fprintf(w, "%s: %s\n", makeEscape(d.Output), strings.Join(prereqs, " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// makeEscape escapes characters that are special in Make rules
+func makeEscape(s string) string {
+	return strings.NewReplacer(
+		"$", "$$",
+		"#", `\#`,
+		" ", `\ `,
+	).Replace(s)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hairyhenderson/gomplate/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepsCmd(t *testing.T) {
+	ctx := t.Context()
+
+	stdout := &bytes.Buffer{}
+	err := Main(ctx, []string{"deps", "-i", `{{ ds "foo" }}`, "-d", "foo=foo.json", "-o", "out.txt", "--format", "make"},
+		nil, stdout, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Regexp(t, `^out\.txt: \S*foo\.json\n$`, stdout.String())
+
+	stdout.Reset()
+	err = Main(ctx, []string{"deps", "-i", `{{ ds "foo" }}`, "-d", "foo=foo.json"},
+		nil, stdout, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), `"datasources": [
+      "foo"
+    ]`)
+
+	err = Main(ctx, []string{"deps", "-i", "hi", "--format", "bogus"}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestWriteMakeDeps(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := writeMakeDeps(out, []gomplate.TemplateDependencies{
+		{Output: "-", Prerequisites: []string{"in.tmpl"}},
+		{Output: "out/my file.txt", Prerequisites: []string{"in/my file.tmpl", "$data#1.json"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "out/my\\ file.txt: in/my\\ file.tmpl $$data\\#1.json\n", out.String())
+}
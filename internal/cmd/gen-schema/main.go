@@ -97,6 +97,10 @@ func dataSourceSchema() *jsonschema.Schema {
 		Description: "URL for the datasource (e.g. file:///data.json, https://example.com/data, env:FOO)",
 	})
 	props.Set("header", httpHeaderSchema())
+	props.Set("cacheTTL", &jsonschema.Schema{
+		Type:        "string",
+		Description: "How long to serve remote content from the persistent cache, e.g. 5m, 1h",
+	})
 	return &jsonschema.Schema{
 		Type:                 "object",
 		Description:          "Data source configuration",
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/hairyhenderson/gomplate/v5"
+	"github.com/spf13/cobra"
+)
+
+// newLintCmd - the 'lint' subcommand, which reports problems in templates
+// without rendering them
+func newLintCmd(stderr io.Writer) *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check templates for problems, without rendering them",
+		Long: `Check templates for problems that would otherwise only be found when
+rendering: unknown functions, undefined datasources and nested templates, and
+use of experimental functions without --experimental. Use of deprecated
+functions is reported as a warning.
+
+Problems are printed as file:line:column: severity: message, and the exit
+status is non-zero when any errors are found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			level := slog.LevelWarn
+			if v, _ := cmd.Flags().GetBool("verbose"); v {
+				level = slog.LevelDebug
+			}
+			initLogger(stderr, level)
+
+			ctx := cmd.Context()
+
+			cfg, err := loadConfig(ctx, cmd, args)
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+
+			issues, err := gomplate.Lint(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			errs := 0
+			for _, issue := range issues {
+				if issue.Severity == gomplate.LintError {
+					errs++
+				}
+
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), issue)
+				if err != nil {
+					return err
+				}
+			}
+
+			if errs > 0 {
+				return fmt.Errorf("found %d error(s) in templates", errs)
+			}
+
+			return nil
+		},
+	}
+
+	lintCmd.Flags().SortFlags = false
+	lintCmd.Flags().Bool("experimental", false, "enable experimental features [$GOMPLATE_EXPERIMENTAL]")
+	initAnalysisFlags(lintCmd)
+
+	return lintCmd
+}
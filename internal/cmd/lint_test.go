@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCmd(t *testing.T) {
+	ctx := t.Context()
+
+	stdout := &bytes.Buffer{}
+	err := Main(ctx, []string{"lint", "-i", `{{ crypto.EncryptAES "k" "v" }}`}, nil, stdout, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, "<arg>:1:4: warning: crypto.EncryptAES is deprecated - use crypto.AESEncrypt instead\n", stdout.String())
+
+	stdout.Reset()
+	err = Main(ctx, []string{"lint", "-i", `{{ net.CIDRHost 1 "10.0.0.0/8" }}{{ nope }}`}, nil, stdout, &bytes.Buffer{})
+	require.EqualError(t, err, "found 2 error(s) in templates")
+	assert.Equal(t, `<arg>:1:4: error: net.CIDRHost is experimental, and experimental mode is not enabled
+<arg>:1:37: error: unknown function "nope"
+`, stdout.String())
+
+	stdout.Reset()
+	err = Main(ctx, []string{"lint", "--experimental", "-i", `{{ net.CIDRHost 1 "10.0.0.0/8" }}`}, nil, stdout, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Empty(t, stdout.String())
+}
@@ -105,6 +105,8 @@ func NewGomplateCmd(stderr io.Writer) *cobra.Command {
 			slog.DebugContext(ctx, "completed rendering",
 				slog.Int("templatesRendered", gomplate.Metrics.TemplatesProcessed),
 				slog.Int("errors", gomplate.Metrics.Errors),
+				slog.Int("datasourceCacheHits", gomplate.Metrics.DatasourceCacheHits),
+				slog.Int("datasourceCacheMisses", gomplate.Metrics.DatasourceCacheMisses),
 				slog.Duration("duration", gomplate.Metrics.TotalRenderDuration))
 
 			if err != nil {
@@ -115,6 +117,9 @@ func NewGomplateCmd(stderr io.Writer) *cobra.Command {
 		},
 		Args: optionalExecArgs,
 	}
+
+	rootCmd.AddCommand(newDepsCmd(stderr), newLintCmd(stderr))
+
 	return rootCmd
 }
 
@@ -127,6 +132,7 @@ func InitFlags(command *cobra.Command) {
 
 	command.Flags().StringSliceP("datasource", "d", nil, "`datasource` in alias=URL form. Specify multiple times to add multiple sources.")
 	command.Flags().StringSliceP("datasource-header", "H", nil, "HTTP `header` field in 'alias=Name: value' form to be provided on HTTP-based data sources. Multiples can be set.")
+	command.Flags().StringSlice("datasource-override", nil, "replace the URL of an already-defined datasource, in alias=URL form. Specify multiple times to override multiple sources.")
 
 	command.Flags().StringSliceP("context", "c", nil, "pre-load a `datasource` into the context, in alias=URL form. Use the special alias `.` to set the root context.")
 
@@ -148,6 +154,17 @@ func InitFlags(command *cobra.Command) {
 
 	command.Flags().Bool("exec-pipe", false, "pipe the output to the post-run exec command")
 
+	command.Flags().Int("parallelism", 1, "maximum `number` of templates to render concurrently")
+
+	command.Flags().Bool("check", false, "render templates without writing any output, and fail if any output file differs from the rendered content")
+	command.Flags().Bool("diff", false, "render templates without writing any output, and print a unified diff of the changes that would be made")
+	command.Flags().Bool("watch", false, "keep running, and re-render templates when inputs, nested templates, or file datasources change")
+
+	command.Flags().String("cache-dir", "", "`directory` for the persistent cache of remote datasources (default: a gomplate directory in the user's cache directory)")
+	command.Flags().Bool("offline", false, "read remote datasources only from the persistent cache")
+	command.Flags().String("record-datasources", "", "record every datasource read into the given fixture `file`")
+	command.Flags().String("replay-datasources", "", "read datasources only from the given fixture `file`, written by --record-datasources")
+
 	// these are only set for the help output - these defaults aren't actually used
 	ldDefault := env.Getenv("GOMPLATE_LEFT_DELIM", "{{")
 	rdDefault := env.Getenv("GOMPLATE_RIGHT_DELIM", "}}")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/hairyhenderson/gomplate/v5/internal/urlhelpers"
 	"github.com/hairyhenderson/yaml"
@@ -27,14 +28,61 @@ func ExperimentalEnabled(ctx context.Context) bool {
 type DataSource struct {
 	URL    *url.URL    `yaml:"-"`
 	Header http.Header `yaml:"header,omitempty,flow"`
+
+	// CacheTTL - how long content read from a remote datasource may be served
+	// from the persistent cache. Zero disables caching.
+	CacheTTL time.Duration `yaml:"cacheTTL,omitempty"`
+
+	// Retries - how many times to retry failed HTTP requests (connection
+	// errors, 429s and 5xx responses). Zero disables retries.
+	Retries int `yaml:"retries,omitempty"`
+	// Timeout - the time limit for each HTTP request. Zero means no limit.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Backoff - the delay before the first retry, doubled for each subsequent
+	// retry. A Retry-After response header takes precedence.
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+
+	// TLS - TLS settings for HTTPS datasources
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+
+	// Method - the HTTP method to use, defaults to GET, or POST when there is
+	// a Body
+	Method string `yaml:"method,omitempty"`
+	// Body - the HTTP request body, sent as JSON unless a Content-Type header
+	// is set. An argument given to the datasource replaces the body.
+	Body string `yaml:"body,omitempty"`
+}
+
+// TLSConfig - TLS settings for HTTPS datasources, for using client
+// certificates (mTLS) or servers with certificates signed by a private CA
+type TLSConfig struct {
+	// CertFile - path to a PEM-encoded client certificate
+	CertFile string `yaml:"certFile,omitempty"`
+	// KeyFile - path to the PEM-encoded private key for CertFile
+	KeyFile string `yaml:"keyFile,omitempty"`
+	// CAFile - path to a PEM-encoded bundle of CA certificates to verify the
+	// server with, instead of the system's certificate pool
+	CAFile string `yaml:"caFile,omitempty"`
+	// ServerName - the server name to verify, if different from the host
+	ServerName string `yaml:"serverName,omitempty"`
+	// InsecureSkipVerify - skip verification of the server's certificate.
+	// Only for testing!
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
 }
 
 // UnmarshalYAML - satisfy the yaml.Umarshaler interface - URLs aren't
 // well supported, and anyway we need to do some extra parsing
 func (d *DataSource) UnmarshalYAML(value *yaml.Node) error {
 	type raw struct {
-		Header http.Header
-		URL    string
+		Header   http.Header
+		URL      string
+		CacheTTL time.Duration `yaml:"cacheTTL"`
+		Retries  int
+		Timeout  time.Duration
+		Backoff  time.Duration
+		TLS      *TLSConfig
+		Method   string
+		Body     string
 	}
 	r := raw{}
 	err := value.Decode(&r)
@@ -46,8 +94,15 @@ func (d *DataSource) UnmarshalYAML(value *yaml.Node) error {
 		return fmt.Errorf("could not parse datasource URL %q: %w", r.URL, err)
 	}
 	*d = DataSource{
-		URL:    u,
-		Header: r.Header,
+		URL:      u,
+		Header:   r.Header,
+		CacheTTL: r.CacheTTL,
+		Retries:  r.Retries,
+		Timeout:  r.Timeout,
+		Backoff:  r.Backoff,
+		TLS:      r.TLS,
+		Method:   r.Method,
+		Body:     r.Body,
 	}
 	return nil
 }
@@ -56,12 +111,26 @@ func (d *DataSource) UnmarshalYAML(value *yaml.Node) error {
 // well supported, and anyway we need to do some extra parsing
 func (d DataSource) MarshalYAML() (any, error) {
 	type raw struct {
-		Header http.Header
-		URL    string
+		Header   http.Header
+		URL      string
+		CacheTTL time.Duration `yaml:"cacheTTL,omitempty"`
+		Retries  int           `yaml:"retries,omitempty"`
+		Timeout  time.Duration `yaml:"timeout,omitempty"`
+		Backoff  time.Duration `yaml:"backoff,omitempty"`
+		TLS      *TLSConfig    `yaml:"tls,omitempty"`
+		Method   string        `yaml:"method,omitempty"`
+		Body     string        `yaml:"body,omitempty"`
 	}
 	r := raw{
-		URL:    d.URL.String(),
-		Header: d.Header,
+		URL:      d.URL.String(),
+		Header:   d.Header,
+		CacheTTL: d.CacheTTL,
+		Retries:  d.Retries,
+		Timeout:  d.Timeout,
+		Backoff:  d.Backoff,
+		TLS:      d.TLS,
+		Method:   d.Method,
+		Body:     d.Body,
 	}
 	return r, nil
 }
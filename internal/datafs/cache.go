@@ -0,0 +1,90 @@
+package datafs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CacheObserver is notified of each datasource cache lookup. The hit parameter
+// is true when the content was already cached, or was already being read by
+// another caller.
+type CacheObserver func(hit bool)
+
+// contentCache is a concurrency-safe cache of datasource content. Concurrent
+// reads of the same key are coalesced, so that the content is only read once.
+// Failed reads are not cached.
+type contentCache struct {
+	entries  map[string]*cacheEntry
+	observer CacheObserver
+	mu       sync.Mutex
+}
+
+// cacheEntry holds the result of a single read - done is closed once the read
+// has completed
+type cacheEntry struct {
+	done chan struct{}
+	c    *content
+	err  error
+}
+
+// get returns the cached content for key, calling read to read it if it's not
+// cached yet. If another caller is already reading the same key, get waits for
+// that read to complete (or for ctx to be done) and returns its result.
+func (c *contentCache) get(ctx context.Context, key string, read func() (*content, error)) (*content, error) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]*cacheEntry{}
+	}
+
+	if e, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		c.notify(true)
+
+		select {
+		case <-e.done:
+			return e.c, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	e := &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+	c.notify(false)
+
+	// waiters must be released even if read panics
+	defer func() {
+		if r := recover(); r != nil {
+			e.err = fmt.Errorf("panic while reading %q: %v", key, r)
+			c.finish(key, e)
+
+			panic(r)
+		}
+
+		c.finish(key, e)
+	}()
+
+	e.c, e.err = read()
+
+	return e.c, e.err
+}
+
+// finish marks the entry's read as completed, releasing any waiters
+func (c *contentCache) finish(key string, e *cacheEntry) {
+	if e.err != nil {
+		// forget failed reads so that later calls can try again
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+	}
+
+	close(e.done)
+}
+
+func (c *contentCache) notify(hit bool) {
+	if c.observer != nil {
+		c.observer(hit)
+	}
+}
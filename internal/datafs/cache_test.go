@@ -0,0 +1,159 @@
+package datafs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentCache(t *testing.T) {
+	var hits, misses atomic.Int32
+	c := &contentCache{observer: func(hit bool) {
+		if hit {
+			hits.Add(1)
+		} else {
+			misses.Add(1)
+		}
+	}}
+
+	var reads atomic.Int32
+	release := make(chan struct{})
+	read := func() (*content, error) {
+		reads.Add(1)
+		<-release
+		return &content{contentType: "text/plain", b: []byte("hello")}, nil
+	}
+
+	// concurrent reads of the same key are coalesced
+	wg := sync.WaitGroup{}
+	for range 10 {
+		wg.Go(func() {
+			fc, err := c.get(t.Context(), "foo", read)
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", string(fc.b))
+		})
+	}
+
+	require.Eventually(t, func() bool {
+		return hits.Load()+misses.Load() == 10
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), reads.Load())
+	assert.Equal(t, int32(9), hits.Load())
+	assert.Equal(t, int32(1), misses.Load())
+
+	// cached from now on
+	fc, err := c.get(t.Context(), "foo", read)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(fc.b))
+	assert.Equal(t, int32(1), reads.Load())
+	assert.Equal(t, int32(10), hits.Load())
+}
+
+func TestContentCache_Error(t *testing.T) {
+	c := &contentCache{}
+
+	_, err := c.get(t.Context(), "foo", func() (*content, error) {
+		return nil, errors.New("boom")
+	})
+	require.Error(t, err)
+
+	// failed reads aren't cached
+	fc, err := c.get(t.Context(), "foo", func() (*content, error) {
+		return &content{b: []byte("ok")}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(fc.b))
+}
+
+func TestContentCache_Panic(t *testing.T) {
+	var hits atomic.Int32
+	c := &contentCache{observer: func(hit bool) {
+		if hit {
+			hits.Add(1)
+		}
+	}}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		defer func() { _ = recover() }()
+
+		_, _ = c.get(t.Context(), "foo", func() (*content, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	<-started
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.get(t.Context(), "foo", func() (*content, error) {
+			return &content{b: []byte("unused")}, nil
+		})
+		errs <- err
+	}()
+
+	require.Eventually(t, func() bool { return hits.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+
+	// waiters are released with an error
+	select {
+	case err := <-errs:
+		require.ErrorContains(t, err, "boom")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "waiter wasn't released")
+	}
+
+	// the panic is propagated to the reader
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = c.get(t.Context(), "bar", func() (*content, error) {
+			panic("boom")
+		})
+	})
+
+	// the failed read isn't cached
+	fc, err := c.get(t.Context(), "foo", func() (*content, error) {
+		return &content{b: []byte("ok")}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(fc.b))
+}
+
+func TestContentCache_WaiterContext(t *testing.T) {
+	c := &contentCache{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	go func() {
+		_, _ = c.get(context.Background(), "foo", func() (*content, error) {
+			close(started)
+			<-release
+			return &content{b: []byte("hello")}, nil
+		})
+	}()
+
+	<-started
+
+	// a waiting caller gives up when its context is done
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.get(ctx, "foo", func() (*content, error) {
+		return &content{b: []byte("unused")}, nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"io"
 	"io/fs"
+	"net/url"
 	"os"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
 )
 
 // withContexter is an fs.FS that can be configured with a custom context
@@ -28,6 +31,25 @@ func WithDataSourceRegistryFS(registry Registry, fsys fs.FS) fs.FS {
 	return fsys
 }
 
+// contentReader reads the content of a datasource - see dsReader.readContent
+type contentReader func(ctx context.Context, u *url.URL, source config.DataSource) (*content, error)
+
+type withContentReaderer interface {
+	WithContentReader(read contentReader) fs.FS
+}
+
+// withContentReaderFS injects a content reader into the filesystem fs, if the
+// filesystem supports it (i.e. has a WithContentReader method). This is used
+// for the mergefs filesystem, so that its parts are read through the
+// persistent cache.
+func withContentReaderFS(read contentReader, fsys fs.FS) fs.FS {
+	if fsys, ok := fsys.(withContentReaderer); ok {
+		return fsys.WithContentReader(read)
+	}
+
+	return fsys
+}
+
 type stdinCtxKey struct{}
 
 // ContextWithStdin injects an [io.Reader] into the context, which can be used
@@ -46,3 +68,22 @@ func StdinFromContext(ctx context.Context) io.Reader {
 
 	return os.Stdin
 }
+
+type stderrCtxKey struct{}
+
+// ContextWithStderr injects an [io.Writer] into the context, which can be used
+// to override the default stderr for commands run by exec datasources.
+func ContextWithStderr(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, stderrCtxKey{}, w)
+}
+
+// StderrFromContext returns the io.Writer that should be used for stderr as
+// injected by [ContextWithStderr]. If no writer has been injected, [os.Stderr]
+// is returned.
+func StderrFromContext(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(stderrCtxKey{}).(io.Writer); ok {
+		return w
+	}
+
+	return os.Stderr
+}
@@ -22,6 +22,8 @@ var DefaultProvider = sync.OnceValue(
 		fsp.Add(EnvFS)
 		fsp.Add(StdinFS)
 		fsp.Add(mergeFSProvider)
+		fsp.Add(SQLiteFS)
+		fsp.Add(ExecFS)
 
 		return fsp
 	})()
@@ -0,0 +1,181 @@
+package datafs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+)
+
+// diskCache is a persistent cache of remote datasource content, shared between
+// runs. Entries may contain secrets, so they're only readable by the current
+// user.
+type diskCache struct {
+	// defaults to a "gomplate" directory in the user's cache directory
+	dir string
+	// only read from the cache, never from the datasource itself
+	offline bool
+}
+
+// diskCacheEntry is the on-disk representation of cached content
+type diskCacheEntry struct {
+	Fetched     time.Time `json:"fetched"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Data        []byte    `json:"data"`
+}
+
+// readContent reads the content of the datasource at u. Remote datasources are
+// read through the persistent cache if they have a CacheTTL, or when offline.
+func (d *dsReader) readContent(ctx context.Context, u *url.URL, source config.DataSource) (*content, error) {
+	if d.disk == nil || !isRemote(u) || (source.CacheTTL <= 0 && !d.disk.offline) {
+		return d.readFileContent(ctx, u, source)
+	}
+
+	path, err := d.disk.path(u, source)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := d.disk.load(path)
+	if err != nil {
+		slog.WarnContext(ctx, "ignoring unreadable datasource cache entry",
+			"url", u.Redacted(), "path", path, "err", err)
+	}
+
+	if d.disk.offline {
+		if entry == nil {
+			return nil, fmt.Errorf("offline, and no cached content for %s", u.Redacted())
+		}
+
+		return &content{contentType: entry.ContentType, b: entry.Data}, nil
+	}
+
+	if entry != nil && time.Since(entry.Fetched) < source.CacheTTL {
+		return &content{contentType: entry.ContentType, b: entry.Data}, nil
+	}
+
+	fc, err := d.readFileContent(ctx, u, source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.disk.store(path, &diskCacheEntry{
+		Fetched:     time.Now(),
+		URL:         u.Redacted(),
+		ContentType: fc.contentType,
+		Data:        fc.b,
+	})
+	if err != nil {
+		// the content is still usable, so caching failures aren't fatal
+		slog.WarnContext(ctx, "failed to cache datasource content",
+			"url", u.Redacted(), "path", path, "err", err)
+	}
+
+	return fc, nil
+}
+
+// isRemote reports whether the URL refers to a datasource that's not available
+// locally, and so may be cached
+func isRemote(u *url.URL) bool {
+	switch u.Scheme {
+	case "", "file", "env", "stdin", "merge", "sqlite", "exec":
+		return false
+	default:
+		return true
+	}
+}
+
+// path returns the path of the cache entry for the given URL and datasource.
+// Headers, and the request method and body, are part of the key since they may
+// change the response.
+func (c *diskCache) path(u *url.URL, source config.DataSource) (string, error) {
+	dir := c.dir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("couldn't determine datasource cache directory: %w", err)
+		}
+
+		dir = filepath.Join(cacheDir, "gomplate")
+	}
+
+	h := sha256.New()
+	h.Write([]byte(u.String()))
+	h.Write([]byte{0})
+	_ = source.Header.Write(h)
+
+	if source.Method != "" || source.Body != "" {
+		h.Write([]byte{0})
+		h.Write([]byte(source.Method))
+		h.Write([]byte{0})
+		h.Write([]byte(source.Body))
+	}
+
+	return filepath.Join(dir, "datasources", hex.EncodeToString(h.Sum(nil))+".json"), nil
+}
+
+// load reads the cache entry at path - a nil entry is returned when there is
+// none
+func (c *diskCache) load(path string) (*diskCacheEntry, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &diskCacheEntry{}
+	err = json.Unmarshal(b, entry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// store writes the cache entry to path. The entry is written to a temporary
+// file first so that concurrent readers never see partial content.
+func (c *diskCache) store(path string, entry *diskCacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
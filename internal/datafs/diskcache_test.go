@@ -0,0 +1,221 @@
+package datafs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	var requests atomic.Int32
+	body := atomic.Value{}
+	body.Store(`{"foo": "bar"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := ContextWithFSProvider(context.Background(), httpfs.FS)
+	dir := t.TempDir()
+
+	// each reader is like a separate run, with an empty in-memory cache
+	newReader := func(ttl time.Duration, offline bool) DataSourceReader {
+		reg := NewRegistry()
+		reg.Register("foo", config.DataSource{URL: mustParseURL(srv.URL + "/foo.json"), CacheTTL: ttl})
+
+		return NewSourceReaderWithOptions(reg, SourceReaderOptions{CacheDir: dir, Offline: offline})
+	}
+
+	// no TTL, so nothing is cached
+	ct, b, err := newReader(0, false).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", ct)
+	assert.JSONEq(t, `{"foo": "bar"}`, string(b))
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, _, err = newReader(0, true).ReadSource(ctx, "foo")
+	require.ErrorContains(t, err, "offline")
+
+	// cached content is served until the TTL expires
+	_, _, err = newReader(time.Hour, false).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	body.Store(`{"foo": "baz"}`)
+
+	ct, b, err = newReader(time.Hour, false).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", ct)
+	assert.JSONEq(t, `{"foo": "bar"}`, string(b))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// offline, the cached content is served regardless of TTL
+	_, b, err = newReader(0, true).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"foo": "bar"}`, string(b))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// an expired entry is refreshed
+	_, b, err = newReader(time.Nanosecond, false).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"foo": "baz"}`, string(b))
+	assert.Equal(t, int32(3), requests.Load())
+
+	_, b, err = newReader(time.Hour, false).ReadSource(ctx, "foo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"foo": "baz"}`, string(b))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestDiskCache_Path(t *testing.T) {
+	c := &diskCache{dir: "/cache"}
+	u := mustParseURL("https://example.com/foo.json")
+
+	p1, err := c.path(u, config.DataSource{})
+	require.NoError(t, err)
+
+	p2, err := c.path(u, config.DataSource{Header: http.Header{"Authorization": {"Bearer foo"}}})
+	require.NoError(t, err)
+	assert.NotEqual(t, p1, p2)
+
+	p3, err := c.path(mustParseURL("https://example.com/bar.json"), config.DataSource{})
+	require.NoError(t, err)
+	assert.NotEqual(t, p1, p3)
+
+	p4, err := c.path(u, config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, p1, p4)
+
+	p5, err := c.path(u, config.DataSource{Method: http.MethodPost, Body: `{"q": "foo"}`})
+	require.NoError(t, err)
+	assert.NotEqual(t, p1, p5)
+
+	p6, err := c.path(u, config.DataSource{Method: http.MethodPost, Body: `{"q": "bar"}`})
+	require.NoError(t, err)
+	assert.NotEqual(t, p5, p6)
+}
+
+func TestDiskCache_Store(t *testing.T) {
+	c := &diskCache{}
+	path := filepath.Join(t.TempDir(), "sub", "entry.json")
+
+	entry, err := c.load(path)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	err = c.store(path, &diskCacheEntry{
+		Fetched:     now,
+		URL:         "https://example.com",
+		ContentType: "text/plain",
+		Data:        []byte("hello"),
+	})
+	require.NoError(t, err)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != osWindows {
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	}
+
+	entry, err = c.load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &diskCacheEntry{
+		Fetched:     now,
+		URL:         "https://example.com",
+		ContentType: "text/plain",
+		Data:        []byte("hello"),
+	}, entry)
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = c.load(path)
+	require.Error(t, err)
+}
+
+func TestIsRemote(t *testing.T) {
+	for _, s := range []string{"foo.json", "file:///foo.json", "env:FOO", "stdin:", "merge:foo|bar"} {
+		assert.False(t, isRemote(mustParseURL(s)), s)
+	}
+
+	for _, s := range []string{"https://example.com", "vault:///secret/foo", "consul://"} {
+		assert.True(t, isRemote(mustParseURL(s)), s)
+	}
+}
+
+func TestDiskCache_Merge(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"foo": "bar"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	wd := wdForTest(t)
+	fsys := WrapWdFS(fstest.MapFS{
+		path.Join(wd, "defaults.yaml"): {Data: []byte("foo: default\nextra: true\n")},
+	})
+
+	mux := fsimpl.NewMux()
+	mux.Add(mergeFSProvider)
+	mux.Add(httpfs.FS)
+	mux.Add(WrappedFSProvider(fsys, "file", ""))
+
+	ctx := ContextWithFSProvider(context.Background(), mux)
+	dir := t.TempDir()
+
+	newReader := func(ttl time.Duration, offline bool) DataSourceReader {
+		reg := NewRegistry()
+		reg.Register("remote", config.DataSource{URL: mustParseURL(srv.URL + "/foo.json"), CacheTTL: ttl})
+		reg.Register("merged", config.DataSource{URL: mustParseURL("merge:remote|defaults.yaml")})
+
+		return NewSourceReaderWithOptions(reg, SourceReaderOptions{CacheDir: dir, Offline: offline})
+	}
+
+	// offline, with nothing cached, the remote part isn't requested
+	_, _, err := newReader(time.Hour, true).ReadSource(ctx, "merged")
+	require.ErrorContains(t, err, "offline")
+	assert.Equal(t, int32(0), requests.Load())
+
+	_, b, err := newReader(time.Hour, false).ReadSource(ctx, "merged")
+	require.NoError(t, err)
+	assert.YAMLEq(t, "foo: bar\nextra: true\n", string(b))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// the part's TTL is honoured
+	_, b, err = newReader(time.Hour, false).ReadSource(ctx, "merged")
+	require.NoError(t, err)
+	assert.YAMLEq(t, "foo: bar\nextra: true\n", string(b))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// and the cached part is used offline
+	_, b, err = newReader(0, true).ReadSource(ctx, "merged")
+	require.NoError(t, err)
+	assert.YAMLEq(t, "foo: bar\nextra: true\n", string(b))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// unregistered remote parts are also read offline from the cache only
+	_, _, err = newReader(0, true).ReadSource(ctx, "merge:"+srv.URL+"/other.json|defaults.yaml")
+	require.ErrorContains(t, err, "offline")
+	assert.Equal(t, int32(1), requests.Load())
+}
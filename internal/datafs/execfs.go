@@ -0,0 +1,215 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v5/internal/exechelpers"
+	"github.com/hairyhenderson/gomplate/v5/internal/urlhelpers"
+)
+
+// defaultExecTimeout is the same as the default plugin timeout
+const defaultExecTimeout = 5 * time.Second
+
+// defaultExecEnv are the environment variables always passed to commands, as
+// many commands won't work without them. Others must be allowed with the "env"
+// parameter.
+var defaultExecEnv = []string{"PATH", "HOME", "USER", "TMPDIR", "SYSTEMROOT", "USERPROFILE", "TEMP", "TMP"}
+
+// newExecFS returns a filesystem that runs a command, with the command's stdout
+// as the content of the "." file. The command is named by the URL - either an
+// opaque URL like "exec:kubectl" or "exec://kubectl" for commands found in the
+// PATH, or "exec:///usr/bin/kubectl" for an absolute path.
+//
+// These query parameters are supported:
+//   - arg: arguments to the command, can be repeated
+//   - env: names of environment variables to pass to the command (in addition
+//     to a few always passed, like PATH and HOME), can be repeated
+//   - timeout: how long to let the command run (as a duration like "30s"),
+//     defaults to 5s
+func newExecFS(u *url.URL) (fs.FS, error) {
+	if u.Scheme != "exec" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	cmd := u.Opaque
+	if cmd == "" {
+		cmd = u.Host + u.Path
+	}
+
+	if cmd == "" || cmd == "/" {
+		return nil, fmt.Errorf("no command given in URL %q", u.Redacted())
+	}
+
+	q := u.Query()
+
+	timeout := defaultExecTimeout
+	if t := q.Get("timeout"); t != "" {
+		var err error
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", t, err)
+		}
+	}
+
+	return &execFS{
+		ctx:     context.Background(),
+		cmd:     cmd,
+		args:    q["arg"],
+		env:     q["env"],
+		timeout: timeout,
+	}, nil
+}
+
+type execFS struct {
+	ctx     context.Context
+	cmd     string
+	args    []string
+	env     []string
+	timeout time.Duration
+}
+
+//nolint:gochecknoglobals
+var ExecFS = fsimpl.FSProviderFunc(newExecFS, "exec")
+
+var (
+	_ fs.FS         = (*execFS)(nil)
+	_ withContexter = (*execFS)(nil)
+)
+
+func (f *execFS) WithContext(ctx context.Context) fs.FS {
+	if ctx == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *execFS) Open(name string) (fs.File, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	b, err := f.run()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: f.cmd, Err: err}
+	}
+
+	return &execFile{
+		fi:   FileInfo(path.Base(f.cmd), int64(len(b)), 0o444, time.Now(), ""),
+		body: bytes.NewReader(b),
+	}, nil
+}
+
+// environ returns the environment for the command - only the default and
+// allowed variables are passed through
+func (f *execFS) environ() []string {
+	env := []string{}
+	for _, k := range slices.Concat(defaultExecEnv, f.env) {
+		if v, ok := lookupEnv(k); ok && !slices.ContainsFunc(env, func(e string) bool {
+			return strings.HasPrefix(e, k+"=")
+		}) {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	return env
+}
+
+// run runs the command, returning its stdout. Process handling is the same as
+// for plugins - signals are forwarded to the command, and its stderr is
+// redirected to the stderr given with ContextWithStderr (or ours).
+func (f *execFS) run() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(f.ctx, f.timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, f.cmd, f.args...)
+	c.Env = f.environ()
+	c.Stderr = StderrFromContext(f.ctx)
+	outBuf := &bytes.Buffer{}
+	c.Stdout = outBuf
+
+	start := time.Now()
+	err := c.Start()
+	if err != nil {
+		return nil, fmt.Errorf("starting command: %w", err)
+	}
+
+	// make sure all signals are propagated
+	defer exechelpers.ForwardSignals(c.Process)()
+
+	err = c.Wait()
+	elapsed := time.Since(start)
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("command timed out after %v: %w", elapsed, ctx.Err())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+
+	return outBuf.Bytes(), nil
+}
+
+type execFile struct {
+	fi   fs.FileInfo
+	body io.Reader
+}
+
+var _ fs.File = (*execFile)(nil)
+
+func (f *execFile) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f *execFile) Read(p []byte) (int, error) {
+	return f.body.Read(p)
+}
+
+func (f *execFile) Close() error {
+	return nil
+}
+
+// IsExecURL returns true if the URL is an exec URL, or a merge URL with an
+// in-line exec URL. Like plugins, commands can only be configured outside of
+// templates - with --datasource, --context, or the config file.
+func IsExecURL(u *url.URL) bool {
+	if u.Scheme != "merge" {
+		return u.Scheme == "exec"
+	}
+
+	for part := range strings.SplitSeq(u.Opaque, "|") {
+		if pu, err := urlhelpers.ParseSourceURL(part); err == nil && IsExecURL(pu) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// execArgsURL adds arguments to an exec URL - this is how datasource arguments
+// are handled for the exec scheme. The arguments are split on whitespace, so
+// use the "arg" parameter for arguments containing spaces.
+func execArgsURL(base url.URL, args string) *url.URL {
+	q := base.Query()
+	for _, a := range strings.Fields(args) {
+		q.Add("arg", a)
+	}
+	base.RawQuery = q.Encode()
+
+	return &base
+}
@@ -0,0 +1,223 @@
+package datafs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecHelperProcess isn't a real test - it's run as a command by the exec
+// tests, and behaves according to its arguments
+func TestExecHelperProcess(_ *testing.T) {
+	if os.Getenv("GOMPLATE_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, a := range args {
+		if a == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	switch args[0] {
+	case "echo":
+		fmt.Print(strings.Join(args[1:], " "))
+	case "env":
+		for _, k := range args[1:] {
+			fmt.Printf("%s=%q\n", k, os.Getenv(k))
+		}
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	case "fail":
+		fmt.Fprint(os.Stderr, "oops")
+		os.Exit(2)
+	}
+
+	os.Exit(0)
+}
+
+func helperURL(t *testing.T, args ...string) *url.URL {
+	t.Helper()
+
+	t.Setenv("GOMPLATE_WANT_HELPER_PROCESS", "1")
+
+	q := url.Values{}
+	q.Add("env", "GOMPLATE_WANT_HELPER_PROCESS")
+	for _, a := range append([]string{"-test.run=TestExecHelperProcess", "--"}, args...) {
+		q.Add("arg", a)
+	}
+
+	exe := os.Args[0]
+	return &url.URL{Scheme: "exec", Opaque: exe, RawQuery: q.Encode()}
+}
+
+func TestExecFS(t *testing.T) {
+	fsys, err := newExecFS(helperURL(t, "echo", "hello", "world"))
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+
+	// only "." can be opened
+	_, err = fsys.Open("foo")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// only allowed environment variables are passed
+	t.Setenv("GOMPLATE_ALLOWED", "yes")
+	t.Setenv("GOMPLATE_SECRET", "shh")
+
+	u := helperURL(t, "env", "GOMPLATE_ALLOWED", "GOMPLATE_SECRET")
+	u.RawQuery += "&env=GOMPLATE_ALLOWED"
+
+	fsys, err = newExecFS(u)
+	require.NoError(t, err)
+
+	b, err = fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.Equal(t, "GOMPLATE_ALLOWED=\"yes\"\nGOMPLATE_SECRET=\"\"\n", string(b))
+
+	// timeouts
+	u = helperURL(t, "sleep")
+	u.RawQuery += "&timeout=100ms"
+
+	fsys, err = newExecFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, ".")
+	require.ErrorContains(t, err, "timed out")
+
+	// failures, with stderr redirected
+	fsys, err = newExecFS(helperURL(t, "fail"))
+	require.NoError(t, err)
+
+	stderr := &strings.Builder{}
+	fsys = fsimpl.WithContextFS(ContextWithStderr(context.Background(), stderr), fsys)
+
+	_, err = fs.ReadFile(fsys, ".")
+	require.ErrorContains(t, err, "exit status 2")
+	assert.Equal(t, "oops", stderr.String())
+
+	// the context is used
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fsys, err = newExecFS(helperURL(t, "echo", "hi"))
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsimpl.WithContextFS(ctx, fsys), ".")
+	require.Error(t, err)
+
+	// a missing command
+	fsys, err = newExecFS(&url.URL{Scheme: "exec", Opaque: "gomplate-no-such-command"})
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, ".")
+	require.Error(t, err)
+
+	_, err = newExecFS(&url.URL{Scheme: "exec", Opaque: "echo", RawQuery: "timeout=soon"})
+	require.Error(t, err)
+
+	_, err = newExecFS(&url.URL{Scheme: "exec"})
+	require.Error(t, err)
+
+	_, err = newExecFS(&url.URL{Scheme: "file", Path: "/bin/echo"})
+	require.Error(t, err)
+}
+
+func TestNewExecFS_URLForms(t *testing.T) {
+	for in, expected := range map[string]string{
+		"exec:kubectl":                "kubectl",
+		"exec:./scripts/data.sh":      "./scripts/data.sh",
+		"exec://terraform?arg=output": "terraform",
+		"exec:///usr/bin/git?arg=log": "/usr/bin/git",
+	} {
+		fsys, err := newExecFS(mustParseURL(in))
+		require.NoError(t, err)
+		assert.Equal(t, expected, fsys.(*execFS).cmd, in)
+	}
+}
+
+func TestReadExecDataSource(t *testing.T) {
+	fsp := fsimpl.NewMux()
+	fsp.Add(ExecFS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	u := helperURL(t, "echo", `{"hello":`)
+	u.RawQuery += "&type=application/json"
+
+	reg := NewRegistry()
+	reg.Register("cmd", config.DataSource{URL: u})
+
+	sr := NewSourceReader(reg)
+
+	// datasource arguments are added as arguments
+	ct, b, err := sr.ReadSource(ctx, "cmd", `"world"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", ct)
+	assert.JSONEq(t, `{"hello": "world"}`, string(b))
+}
+
+func TestMergeExecDataSource(t *testing.T) {
+	fsp := fsimpl.NewMux()
+	fsp.Add(ExecFS)
+	fsp.Add(mergeFSProvider)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	u := helperURL(t, "echo", `{"hello": "world"}`)
+	u.RawQuery += "&type=application/json"
+
+	reg := NewRegistry()
+	reg.Register("cmd", config.DataSource{URL: u})
+	reg.Register("other", config.DataSource{URL: mustParseURL("env:///GOMPLATE_NOPE?type=application/json")})
+	reg.Register("merged", config.DataSource{URL: mustParseURL("merge:cmd|cmd")})
+	reg.Register("inline", config.DataSource{URL: mustParseURL("merge:cmd|" + u.String())})
+
+	sr := NewSourceReader(reg)
+
+	// commands defined with an alias can be merged
+	_, b, err := sr.ReadSource(ctx, "merged")
+	require.NoError(t, err)
+	assert.YAMLEq(t, "hello: world\n", string(b))
+
+	// but not in-line commands, which would otherwise be a way for templates
+	// to run commands
+	_, _, err = sr.ReadSource(ctx, "inline")
+	require.ErrorContains(t, err, "must be defined with an alias")
+}
+
+func TestReadSource_ExecArgument(t *testing.T) {
+	fsp := fsimpl.NewMux()
+	fsp.Add(ExecFS)
+	fsp.Add(httpfs.FS)
+	fsp.Add(WrappedFSProvider(fstest.MapFS{}, "file", ""))
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	reg := NewRegistry()
+	reg.Register("dir", config.DataSource{URL: mustParseURL("file:///tmp/ex/")})
+	reg.Register("api", config.DataSource{URL: mustParseURL("https://example.com/api/")})
+
+	sr := NewSourceReader(reg)
+
+	// an absolute URL given as an argument mustn't replace the datasource's
+	// URL, otherwise any datasource could be used to run a command
+	cmd := helperURL(t, "echo", "pwned").String()
+	for _, alias := range []string{"dir", "api"} {
+		_, _, err := sr.ReadSource(ctx, alias, cmd)
+		require.ErrorContains(t, err, "must not change the datasource's scheme", alias)
+	}
+}
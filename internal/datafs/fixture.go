@@ -0,0 +1,137 @@
+package datafs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Fixture is a set of recorded datasource reads, which can be replayed later
+// to render templates without access to the datasources themselves.
+type Fixture struct {
+	reads map[string]FixtureRead
+	mu    sync.Mutex
+}
+
+// FixtureRead is a single recorded datasource read
+type FixtureRead struct {
+	Alias string   `json:"alias"`
+	Args  []string `json:"args,omitempty"`
+	// the resolved URL that was read - informational only, credentials are
+	// redacted
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	// content is stored as text when possible, for readability
+	Text string `json:"text,omitempty"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// fixtureFile is the on-disk representation of a Fixture
+type fixtureFile struct {
+	Reads []FixtureRead `json:"reads"`
+}
+
+// NewFixture creates an empty Fixture, for recording
+func NewFixture() *Fixture {
+	return &Fixture{reads: map[string]FixtureRead{}}
+}
+
+// LoadFixture reads a Fixture previously written with Save
+func LoadFixture(path string) (*Fixture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read datasource fixture: %w", err)
+	}
+
+	ff := fixtureFile{}
+	err = json.Unmarshal(b, &ff)
+	if err != nil {
+		return nil, fmt.Errorf("parse datasource fixture %q: %w", path, err)
+	}
+
+	f := NewFixture()
+	for _, r := range ff.Reads {
+		f.reads[fixtureKey(r.Alias, r.Args)] = r
+	}
+
+	return f, nil
+}
+
+// Save writes the recorded reads to path. Reads are sorted so that recording
+// the same reads always produces the same file. The file may contain secrets,
+// so it's only readable by the current user.
+func (f *Fixture) Save(path string) error {
+	f.mu.Lock()
+	ff := fixtureFile{Reads: make([]FixtureRead, 0, len(f.reads))}
+	for _, r := range f.reads {
+		ff.Reads = append(ff.Reads, r)
+	}
+	f.mu.Unlock()
+
+	slices.SortFunc(ff.Reads, func(a, b FixtureRead) int {
+		return strings.Compare(fixtureKey(a.Alias, a.Args), fixtureKey(b.Alias, b.Args))
+	})
+
+	b, err := json.MarshalIndent(ff, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	b = append(b, '\n')
+
+	err = os.WriteFile(path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("write datasource fixture: %w", err)
+	}
+
+	return nil
+}
+
+// record adds a successful read to the fixture
+func (f *Fixture) record(alias string, args []string, u *url.URL, fc *content) {
+	r := FixtureRead{
+		Alias:       alias,
+		Args:        slices.Clone(args),
+		URL:         u.Redacted(),
+		ContentType: fc.contentType,
+	}
+	if utf8.Valid(fc.b) {
+		r.Text = string(fc.b)
+	} else {
+		r.Data = fc.b
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reads[fixtureKey(alias, args)] = r
+}
+
+// replay returns the recorded content for the read, or an error if it wasn't
+// recorded
+func (f *Fixture) replay(alias string, args []string) (*content, error) {
+	f.mu.Lock()
+	r, ok := f.reads[fixtureKey(alias, args)]
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no recorded read of datasource '%s' with args %q in fixture", alias, args)
+	}
+
+	b := r.Data
+	if b == nil {
+		b = []byte(r.Text)
+	}
+
+	return &content{contentType: r.ContentType, b: b}, nil
+}
+
+// fixtureKey identifies a read - args are joined with a separator that can't
+// appear in them, so that different args can't produce the same key
+func fixtureKey(alias string, args []string) string {
+	return strings.Join(append([]string{alias}, args...), "\x00")
+}
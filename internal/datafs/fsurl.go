@@ -30,6 +30,10 @@ func SplitFSMuxURL(in *url.URL) (*url.URL, string) {
 		}
 
 		return &url.URL{Scheme: u.Scheme, Path: "/"}, strings.TrimLeft(u.Path, "/")
+	case "sqlite", "exec":
+		// the filesystem is the database (and the query) or the command, so
+		// there's no separate path
+		return &u, "."
 	}
 
 	// trim leading and trailing slashes - they are not part of a valid path
@@ -29,6 +29,16 @@ func TestSplitFSMuxURL(t *testing.T) {
 			"vault:///",
 			"secret/a/b/c",
 		},
+		{
+			"exec:kubectl?arg=get&arg=pods",
+			"exec:kubectl?arg=get&arg=pods",
+			".",
+		},
+		{
+			"sqlite:///tmp/inventory.db?query=SELECT+1",
+			"sqlite:///tmp/inventory.db?query=SELECT+1",
+			".",
+		},
 		{
 			"vault:///secret/a/b/",
 			"vault:///",
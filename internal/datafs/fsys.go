@@ -57,6 +57,8 @@ func FSysForPath(ctx context.Context, path string) (fs.FS, error) {
 		// no-op, these are handled
 	case "aws+sm":
 		// An aws+sm URL can be opaque, best not disturb it
+	case "sqlite", "exec":
+		// the path names the database file or command, and must be kept
 	case "", "file", "git+file":
 		// default to "/" so we have a rooted filesystem for all schemes, but also
 		// support volumes on Windows
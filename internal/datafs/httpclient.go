@@ -0,0 +1,356 @@
+package datafs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+)
+
+// defaultBackoff is the delay before the first retry, when retries are
+// configured without a backoff
+const defaultBackoff = time.Second
+
+// maxRetryDelay is the longest delay between retries, regardless of the
+// backoff or a Retry-After header
+const maxRetryDelay = time.Minute
+
+// httpClientFor returns an HTTP client configured with the datasource's TLS,
+// retry, timeout, and backoff settings, or nil when none are set, so that the
+// filesystem's default client is used.
+func httpClientFor(source config.DataSource) (*http.Client, error) {
+	if source.Retries <= 0 && source.Timeout <= 0 && source.TLS == nil {
+		return nil, nil
+	}
+
+	next := http.DefaultTransport
+	if source.TLS != nil {
+		var err error
+		next, err = tlsTransport(*source.TLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if source.Retries <= 0 && source.Timeout <= 0 {
+		return &http.Client{Transport: next}, nil
+	}
+
+	backoff := source.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{
+			next:    next,
+			retries: max(source.Retries, 0),
+			timeout: source.Timeout,
+			backoff: backoff,
+		},
+	}, nil
+}
+
+// tlsTransports caches transports by TLS configuration, so connections can be
+// re-used across reads
+//
+//nolint:gochecknoglobals
+var tlsTransports sync.Map
+
+// tlsTransportKey identifies a cached transport. The modification times of the
+// certificate, key, and CA files are part of the key, so that rotated files are
+// loaded again (for example, between renders with --watch).
+type tlsTransportKey struct {
+	cfg     config.TLSConfig
+	certMod time.Time
+	keyMod  time.Time
+	caMod   time.Time
+}
+
+func newTLSTransportKey(cfg config.TLSConfig) tlsTransportKey {
+	return tlsTransportKey{
+		cfg:     cfg,
+		certMod: fileModTime(cfg.CertFile),
+		keyMod:  fileModTime(cfg.KeyFile),
+		caMod:   fileModTime(cfg.CAFile),
+	}
+}
+
+// fileModTime returns the file's modification time, or the zero time if it
+// can't be determined - errors are reported when the file is read
+func fileModTime(name string) time.Time {
+	if name == "" {
+		return time.Time{}
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return fi.ModTime()
+}
+
+// tlsTransport returns a transport using the given TLS settings, otherwise
+// the same as http.DefaultTransport
+func tlsTransport(cfg config.TLSConfig) (http.RoundTripper, error) {
+	key := newTLSTransportKey(cfg)
+	if t, ok := tlsTransports.Load(key); ok {
+		return t.(http.RoundTripper), nil
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	t := baseTransport()
+	t.TLSClientConfig = tlsConfig
+
+	actual, _ := tlsTransports.LoadOrStore(key, t)
+
+	return actual.(http.RoundTripper), nil
+}
+
+// baseTransport returns a copy of http.DefaultTransport, or a transport with
+// the same settings if it's been replaced with a different type
+func baseTransport() *http.Transport {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		return t.Clone()
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+func buildTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	//nolint:gosec // InsecureSkipVerify is opt-in, and documented as such
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("both certFile and keyFile must be set for a TLS client certificate")
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.CAFile != "" {
+		b, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %q", cfg.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// retryTransport is an http.RoundTripper that retries requests failing with a
+// connection error, a 429, or a 5xx response, with exponential backoff. A
+// Retry-After header in the response is honoured in place of the backoff.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	timeout time.Duration
+	backoff time.Duration
+
+	// maxDelay is the longest delay between attempts, defaults to
+	// maxRetryDelay
+	maxDelay time.Duration
+}
+
+var _ http.RoundTripper = (*retryTransport)(nil)
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// requests with bodies can only be retried if the body can be re-read
+	retries := t.retries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req.Clone(ctx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.roundTrip(r)
+		if attempt >= retries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			// drain the body so the connection can be re-used
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		slog.DebugContext(ctx, "retrying HTTP request",
+			"url", req.URL.Redacted(), "attempt", attempt+1, "delay", delay,
+			"status", statusOf(resp), "err", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns how long to wait before retrying - the backoff doubles with
+// each attempt, and is replaced by the response's Retry-After header if there
+// is one, but the delay is never longer than the maximum
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	maxDelay := t.maxDelay
+	if maxDelay <= 0 {
+		maxDelay = maxRetryDelay
+	}
+
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, maxDelay)
+		}
+	}
+
+	// doubling one step at a time avoids overflowing
+	delay := t.backoff
+	for range attempt {
+		if delay >= maxDelay {
+			break
+		}
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+// roundTrip makes a single attempt, limited by the timeout if one is set
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+			return nil, fmt.Errorf("request timed out after %v: %w", t.timeout, err)
+		}
+
+		return nil, err
+	}
+
+	// the timeout also applies to reading the body, so only cancel once
+	// the body is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// shouldRetry returns true for connection errors (but not cancellation of the
+// request's context), for 429 Too Many Requests, and for 5xx responses other
+// than 501 Not Implemented
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusNotImplemented:
+		return false
+	default:
+		return resp.StatusCode >= 500
+	}
+}
+
+// retryAfter parses a Retry-After header, which may be a number of seconds or
+// an HTTP date
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		// very large values would overflow
+		secs = min(max(secs, 0), math.MaxInt64/int64(time.Second))
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+
+	return resp.StatusCode
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+
+	return c.ReadCloser.Close()
+}
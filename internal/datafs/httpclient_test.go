@@ -0,0 +1,452 @@
+package datafs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer responds with the given status codes in turn, then with 200s
+func flakyServer(t *testing.T, hdr http.Header, codes ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(codes) {
+			for k, v := range hdr {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(codes[n-1])
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodHead {
+			w.Write([]byte(`{"hello": "world"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, calls
+}
+
+func TestHTTPClientFor(t *testing.T) {
+	client, err := httpClientFor(config.DataSource{})
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	client, err = httpClientFor(config.DataSource{Backoff: time.Second})
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	client, err = httpClientFor(config.DataSource{Retries: 3})
+	require.NoError(t, err)
+	assert.Equal(t, &retryTransport{
+		next:    http.DefaultTransport,
+		retries: 3,
+		backoff: defaultBackoff,
+	}, client.Transport)
+
+	client, err = httpClientFor(config.DataSource{Timeout: time.Second, Backoff: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, &retryTransport{
+		next:    http.DefaultTransport,
+		timeout: time.Second,
+		backoff: time.Minute,
+	}, client.Transport)
+
+	tlsCfg := &config.TLSConfig{ServerName: "example.com"}
+
+	client, err = httpClientFor(config.DataSource{TLS: tlsCfg})
+	require.NoError(t, err)
+	require.IsType(t, &http.Transport{}, client.Transport)
+	assert.Equal(t, "example.com", client.Transport.(*http.Transport).TLSClientConfig.ServerName)
+
+	// transports are re-used
+	other, err := httpClientFor(config.DataSource{TLS: &config.TLSConfig{ServerName: "example.com"}, Retries: 1})
+	require.NoError(t, err)
+	assert.Same(t, client.Transport, other.Transport.(*retryTransport).next)
+
+	_, err = httpClientFor(config.DataSource{TLS: &config.TLSConfig{CertFile: "cert.pem"}})
+	require.ErrorContains(t, err, "keyFile")
+
+	_, err = httpClientFor(config.DataSource{TLS: &config.TLSConfig{CAFile: "/nonexistent/ca.pem"}})
+	require.Error(t, err)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("hello"), 0o600))
+
+	_, err = httpClientFor(config.DataSource{TLS: &config.TLSConfig{CAFile: notPEM}})
+	require.ErrorContains(t, err, "no certificates")
+}
+
+func TestRetryTransport(t *testing.T) {
+	client, err := httpClientFor(config.DataSource{Retries: 2, Backoff: time.Millisecond})
+	require.NoError(t, err)
+
+	t.Run("retries 429s and 5xx responses", func(t *testing.T) {
+		srv, calls := flakyServer(t, nil, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		srv, calls := flakyServer(t, nil, 502, 503, 504)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		for _, code := range []int{http.StatusNotFound, http.StatusNotImplemented} {
+			srv, calls := flakyServer(t, nil, code)
+
+			resp, err := client.Get(srv.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, code, resp.StatusCode)
+			assert.EqualValues(t, 1, calls.Load())
+		}
+	})
+
+	t.Run("honours Retry-After", func(t *testing.T) {
+		srv, calls := flakyServer(t, http.Header{"Retry-After": {"1"}}, http.StatusServiceUnavailable)
+
+		start := time.Now()
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 2, calls.Load())
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		srv, _ := flakyServer(t, http.Header{"Retry-After": {"3600"}}, http.StatusServiceUnavailable)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.Do(req)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("re-sends request bodies", func(t *testing.T) {
+		bodies := []string{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"hello", "hello"}, bodies)
+	})
+}
+
+func TestRetryTransport_Timeout(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(time.Second)
+		}
+		w.Write([]byte("hi"))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := httpClientFor(config.DataSource{Timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = client.Get(srv.URL)
+	require.ErrorContains(t, err, "timed out")
+
+	// the timeout applies to each attempt
+	calls.Store(0)
+	client, err = httpClientFor(config.DataSource{Timeout: 50 * time.Millisecond, Retries: 1, Backoff: time.Millisecond})
+	require.NoError(t, err)
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(b))
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestRetryAfter(t *testing.T) {
+	d, ok := retryAfter("")
+	assert.False(t, ok)
+	assert.Zero(t, d)
+
+	d, ok = retryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = retryAfter("-1")
+	assert.True(t, ok)
+	assert.Zero(t, d)
+
+	d, ok = retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, d, float64(5*time.Second))
+
+	d, ok = retryAfter("Mon, 01 Jan 2001 00:00:00 GMT")
+	assert.True(t, ok)
+	assert.Zero(t, d)
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+
+	// very large values don't overflow
+	d, ok = retryAfter("99999999999999999")
+	assert.True(t, ok)
+	assert.Positive(t, d)
+}
+
+func TestRetryTransport_Delay(t *testing.T) {
+	rt := &retryTransport{backoff: time.Second}
+
+	assert.Equal(t, time.Second, rt.delay(0, nil))
+	assert.Equal(t, 8*time.Second, rt.delay(3, nil))
+
+	// the backoff is capped, even after many attempts
+	assert.Equal(t, maxRetryDelay, rt.delay(10, nil))
+	assert.Equal(t, maxRetryDelay, rt.delay(100, nil))
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"5"}}}
+	assert.Equal(t, 5*time.Second, rt.delay(3, resp))
+
+	// and so is Retry-After
+	resp.Header.Set("Retry-After", "99999999999999999")
+	assert.Equal(t, maxRetryDelay, rt.delay(0, resp))
+
+	rt.maxDelay = 10 * time.Millisecond
+	assert.Equal(t, 10*time.Millisecond, rt.delay(0, resp))
+	assert.Equal(t, 10*time.Millisecond, rt.delay(64, nil))
+}
+
+func TestRetryTransport_LargeRetryAfter(t *testing.T) {
+	srv, calls := flakyServer(t, http.Header{"Retry-After": {"99999999999999999"}}, http.StatusServiceUnavailable)
+
+	client := &http.Client{Transport: &retryTransport{
+		next:     http.DefaultTransport,
+		retries:  1,
+		backoff:  time.Millisecond,
+		maxDelay: 10 * time.Millisecond,
+	}}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestReadFileContent_Retries(t *testing.T) {
+	srv, calls := flakyServer(t, nil, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	sr := &dsReader{Registry: NewRegistry()}
+
+	u := mustParseURL(srv.URL + "/foo.json")
+
+	_, err := sr.readFileContent(ctx, u, config.DataSource{})
+	require.Error(t, err)
+
+	calls.Store(0)
+
+	fc, err := sr.readFileContent(ctx, u, config.DataSource{Retries: 2, Backoff: time.Millisecond})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hello": "world"}`, string(fc.b))
+	assert.Equal(t, "application/json", fc.contentType)
+}
+
+// writeClientCert creates a self-signed client certificate and key, returning
+// the paths to their PEM files, and a pool containing the certificate
+func writeClientCert(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gomplate-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	require.NoError(t, os.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return certFile, keyFile, pool
+}
+
+func TestReadFileContent_TLS(t *testing.T) {
+	certFile, keyFile, clientCAs := writeClientCert(t)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"client": %q}`, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	sr := &dsReader{Registry: NewRegistry()}
+
+	u := mustParseURL(srv.URL + "/foo.json")
+
+	// the server's certificate isn't trusted
+	_, err := sr.readFileContent(ctx, u, config.DataSource{})
+	require.Error(t, err)
+
+	// no client certificate
+	_, err = sr.readFileContent(ctx, u, config.DataSource{
+		TLS: &config.TLSConfig{CAFile: caFile},
+	})
+	require.Error(t, err)
+
+	fc, err := sr.readFileContent(ctx, u, config.DataSource{
+		TLS: &config.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"client": "gomplate-test-client"}`, string(fc.b))
+
+	// the test server's certificate is valid for "example.com"
+	fc, err = sr.readFileContent(ctx, u, config.DataSource{
+		TLS: &config.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile, ServerName: "example.com"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"client": "gomplate-test-client"}`, string(fc.b))
+
+	_, err = sr.readFileContent(ctx, u, config.DataSource{
+		TLS: &config.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile, ServerName: "gomplate.ca"},
+	})
+	require.Error(t, err)
+
+	fc, err = sr.readFileContent(ctx, u, config.DataSource{
+		TLS: &config.TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: true},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"client": "gomplate-test-client"}`, string(fc.b))
+}
+
+func TestTLSTransport(t *testing.T) {
+	certFile, keyFile, _ := writeClientCert(t)
+	cfg := config.TLSConfig{CertFile: certFile, KeyFile: keyFile}
+
+	t1, err := tlsTransport(cfg)
+	require.NoError(t, err)
+
+	t2, err := tlsTransport(cfg)
+	require.NoError(t, err)
+	assert.Same(t, t1, t2)
+
+	// rotated certificates are loaded again
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	t3, err := tlsTransport(cfg)
+	require.NoError(t, err)
+	assert.NotSame(t, t1, t3)
+
+	// a replaced http.DefaultTransport is handled
+	orig := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	http.DefaultTransport = &retryTransport{next: orig}
+
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	t4, err := tlsTransport(cfg)
+	require.NoError(t, err)
+	require.IsType(t, &http.Transport{}, t4)
+	assert.NotNil(t, t4.(*http.Transport).TLSClientConfig)
+	assert.NotNil(t, t4.(*http.Transport).Proxy)
+}
@@ -0,0 +1,179 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+)
+
+// isHTTPRequest reports whether the datasource is an HTTP datasource with a
+// custom method or a request body, which must be read with an httpRequestFS
+// instead of the usual HTTP filesystem (which only makes GET and HEAD requests)
+func isHTTPRequest(u *url.URL, source config.DataSource) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	return source.Body != "" || (source.Method != "" && !strings.EqualFold(source.Method, http.MethodGet))
+}
+
+// httpRequestFSFor returns a filesystem that makes requests with the
+// datasource's method and body, or fsys when these aren't needed
+func httpRequestFSFor(u *url.URL, source config.DataSource, fsys fs.FS) fs.FS {
+	if !isHTTPRequest(u, source) {
+		return fsys
+	}
+
+	method := strings.ToUpper(source.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	return &httpRequestFS{
+		ctx:    context.Background(),
+		client: http.DefaultClient,
+		base:   u,
+		header: http.Header{},
+		method: method,
+		body:   source.Body,
+	}
+}
+
+// httpRequestFS is a filesystem that makes a single request for each file
+// opened, using a configurable method and request body. Request bodies are
+// sent as JSON, unless a Content-Type header is given.
+type httpRequestFS struct {
+	ctx    context.Context
+	client *http.Client
+	base   *url.URL
+	header http.Header
+	method string
+	body   string
+}
+
+var (
+	_ fs.FS         = (*httpRequestFS)(nil)
+	_ withContexter = (*httpRequestFS)(nil)
+)
+
+func (f *httpRequestFS) WithContext(ctx context.Context) fs.FS {
+	if ctx == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *httpRequestFS) WithHeader(header http.Header) fs.FS {
+	if header == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.header = header.Clone()
+
+	return &fsys
+}
+
+func (f *httpRequestFS) WithHTTPClient(client *http.Client) fs.FS {
+	if client == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.client = client
+
+	return &fsys
+}
+
+func (f *httpRequestFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	u := f.base.JoinPath(name)
+
+	b, fi, err := f.request(u, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &httpRequestFile{fi: fi, body: bytes.NewReader(b)}, nil
+}
+
+// request makes the request, returning the response body and file info
+func (f *httpRequestFS) request(u *url.URL, name string) ([]byte, fs.FileInfo, error) {
+	var body io.Reader
+	if f.body != "" {
+		body = strings.NewReader(f.body)
+	}
+
+	req, err := http.NewRequestWithContext(f.ctx, f.method, u.String(), body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header = f.header.Clone()
+	if f.body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", iohelpers.JSONMimetype)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("http %s failed with status %d", f.method, resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	modTime := time.Time{}
+	if mod := resp.Header.Get("Last-Modified"); mod != "" {
+		// best-effort - if it can't be parsed, just ignore it
+		modTime, _ = http.ParseTime(mod)
+	}
+
+	if name == "." {
+		name = path.Base(u.Path)
+	}
+
+	return b, FileInfo(name, int64(len(b)), 0o444, modTime, resp.Header.Get("Content-Type")), nil
+}
+
+type httpRequestFile struct {
+	fi   fs.FileInfo
+	body io.Reader
+}
+
+var _ fs.File = (*httpRequestFile)(nil)
+
+func (f *httpRequestFile) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f *httpRequestFile) Read(p []byte) (int, error) {
+	return f.body.Read(p)
+}
+
+func (f *httpRequestFile) Close() error {
+	return nil
+}
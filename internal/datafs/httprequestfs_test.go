@@ -0,0 +1,200 @@
+package datafs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer responds with a JSON description of the request
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"query":       r.URL.RawQuery,
+			"body":        string(b),
+			"contentType": r.Header.Get("Content-Type"),
+			"foo":         r.Header.Get("Foo"),
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestIsHTTPRequest(t *testing.T) {
+	u := mustParseURL("https://example.com/api")
+
+	assert.False(t, isHTTPRequest(u, config.DataSource{}))
+	assert.False(t, isHTTPRequest(u, config.DataSource{Method: "get"}))
+	assert.True(t, isHTTPRequest(u, config.DataSource{Method: "PUT"}))
+	assert.True(t, isHTTPRequest(u, config.DataSource{Body: "{}"}))
+	assert.True(t, isHTTPRequest(mustParseURL("http://example.com"), config.DataSource{Method: "post"}))
+	assert.False(t, isHTTPRequest(mustParseURL("vault:///secret/foo"), config.DataSource{Body: "{}"}))
+
+	fsys, err := httpfs.New(u)
+	require.NoError(t, err)
+	assert.Same(t, fsys, httpRequestFSFor(u, config.DataSource{}, fsys))
+}
+
+func TestHTTPRequestFS(t *testing.T) {
+	srv := echoServer(t)
+
+	u := mustParseURL(srv.URL + "/?a=b")
+
+	// the body is sent as JSON, with POST by default
+	fsys := httpRequestFSFor(u, config.DataSource{Body: `{"query": "{ hello }"}`}, nil)
+	fsys = fsimpl.WithHeaderFS(http.Header{"Foo": {"bar"}}, fsys)
+
+	f, err := fsys.Open("graphql")
+	require.NoError(t, err)
+	defer f.Close()
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, "graphql", fi.Name())
+	assert.Equal(t, "application/json", contentType(nil, fi))
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "POST", "path": "/graphql", "query": "a=b",
+		"body": "{\"query\": \"{ hello }\"}", "contentType": "application/json",
+		"foo": "bar"
+	}`, string(b))
+
+	// the method and content type can be set
+	fsys = httpRequestFSFor(u, config.DataSource{Method: "put", Body: "hello"}, nil)
+	fsys = fsimpl.WithHeaderFS(http.Header{"Content-Type": {"text/plain"}}, fsys)
+
+	b, err = fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "PUT", "path": "/", "query": "a=b",
+		"body": "hello", "contentType": "text/plain", "foo": ""
+	}`, string(b))
+
+	// no body
+	fsys = httpRequestFSFor(u, config.DataSource{Method: http.MethodDelete}, nil)
+
+	b, err = fs.ReadFile(fsys, "items/1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "DELETE", "path": "/items/1", "query": "a=b",
+		"body": "", "contentType": "", "foo": ""
+	}`, string(b))
+
+	_, err = fsys.Open("missing")
+	require.ErrorContains(t, err, "status 404")
+
+	_, err = fsys.Open("../foo")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+
+	// the context is used
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = fs.ReadFile(fsimpl.WithContextFS(ctx, fsys), ".")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = fs.ReadFile(httpRequestFSFor(u, config.DataSource{Method: "NOT A METHOD"}, nil), ".")
+	require.Error(t, err)
+}
+
+func TestReadHTTPRequestDataSource(t *testing.T) {
+	srv := echoServer(t)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	reg := NewRegistry()
+	reg.Register("search", config.DataSource{
+		URL:    mustParseURL(srv.URL + "/search"),
+		Method: http.MethodPost,
+		Body:   `{"q": "default"}`,
+	})
+
+	sr := NewSourceReader(reg)
+
+	ct, b, err := sr.ReadSource(ctx, "search")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", ct)
+	assert.JSONEq(t, `{
+		"method": "POST", "path": "/search", "query": "",
+		"body": "{\"q\": \"default\"}", "contentType": "application/json", "foo": ""
+	}`, string(b))
+
+	// the argument replaces the body, rather than being a sub-path
+	_, b, err = sr.ReadSource(ctx, "search", `{"q": "gomplate"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "POST", "path": "/search", "query": "",
+		"body": "{\"q\": \"gomplate\"}", "contentType": "application/json", "foo": ""
+	}`, string(b))
+}
+
+func TestMergeFS_HTTPRequest(t *testing.T) {
+	srv := echoServer(t)
+
+	wd := wdForTest(t)
+
+	fsys := WrapWdFS(fstest.MapFS{
+		path.Join(wd, "defaults.yaml"): {Data: []byte("method: GET\nextra: true\n")},
+	})
+
+	mux := fsimpl.NewMux()
+	mux.Add(mergeFSProvider)
+	mux.Add(httpfs.FS)
+	mux.Add(WrappedFSProvider(fsys, "file", ""))
+
+	ctx := ContextWithFSProvider(context.Background(), mux)
+
+	reg := NewRegistry()
+	reg.Register("api", config.DataSource{
+		URL:    mustParseURL(srv.URL + "/api"),
+		Method: http.MethodPost,
+		Body:   `{}`,
+	})
+
+	mfsys, err := newMergeFS(mustParseURL("merge:///"))
+	require.NoError(t, err)
+
+	mfsys = WithDataSourceRegistryFS(reg, mfsys)
+	mfsys = fsimpl.WithContextFS(ctx, mfsys)
+
+	b, err := fs.ReadFile(mfsys, "api|defaults.yaml")
+	require.NoError(t, err)
+	assert.YAMLEq(t, `
+body: "{}"
+contentType: application/json
+extra: true
+foo: ""
+method: POST
+path: /api
+query: ""
+`, string(b))
+}
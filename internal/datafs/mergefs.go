@@ -46,6 +46,7 @@ type mergeFS struct {
 	ctx        context.Context
 	httpClient *http.Client
 	registry   Registry
+	read       contentReader
 }
 
 //nolint:gochecknoglobals
@@ -55,6 +56,7 @@ var (
 	_ fs.FS                    = (*mergeFS)(nil)
 	_ withContexter            = (*mergeFS)(nil)
 	_ withDataSourceRegistryer = (*mergeFS)(nil)
+	_ withContentReaderer      = (*mergeFS)(nil)
 )
 
 func (f *mergeFS) WithContext(ctx context.Context) fs.FS {
@@ -90,6 +92,17 @@ func (f *mergeFS) WithDataSourceRegistry(registry Registry) fs.FS {
 	return &fsys
 }
 
+func (f *mergeFS) WithContentReader(read contentReader) fs.FS {
+	if read == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.read = read
+
+	return &fsys
+}
+
 func (f *mergeFS) Open(name string) (fs.File, error) {
 	parts := strings.Split(name, "|")
 	if len(parts) < 2 {
@@ -113,11 +126,36 @@ func (f *mergeFS) Open(name string) (fs.File, error) {
 			if uerr != nil {
 				return nil, fmt.Errorf("unknown datasource %q, and couldn't parse URL: %w", part, uerr)
 			}
+
+			// commands can only be run by datasources that were defined
+			// outside of templates, so must be referred to by alias
+			if IsExecURL(u) {
+				return nil, &fs.PathError{
+					Op: "open", Path: name,
+					Err: fmt.Errorf("exec datasource %q must be defined with an alias to be merged", part),
+				}
+			}
 			subSource = config.DataSource{URL: u}
 		}
 
 		u := subSource.URL
 
+		// remote parts are read through the content reader when there is one,
+		// so that the persistent cache (and offline mode) applies to them too
+		if f.read != nil && isRemote(u) {
+			fc, err := f.read(f.ctx, u, subSource)
+			if err != nil {
+				return nil, &fs.PathError{
+					Op: "open", Path: name,
+					Err: fmt.Errorf("reading merge part %q: %w", part, err),
+				}
+			}
+
+			subFiles[i] = subFile{newContentFile(part, fc), fc.contentType}
+
+			continue
+		}
+
 		// possible type hint in the type query param. Contrary to spec, we allow
 		// unescaped '+' characters to make it simpler to provide types like
 		// "application/array+json"
@@ -145,11 +183,24 @@ func (f *mergeFS) Open(name string) (fs.File, error) {
 			}
 		}
 
+		fsys = httpRequestFSFor(fsURL, subSource, fsys)
+
 		// pass in the context and other bits
 		fsys = fsimpl.WithContextFS(f.ctx, fsys)
 		fsys = fsimpl.WithHeaderFS(subSource.Header, fsys)
 
-		fsys = fsimpl.WithHTTPClientFS(f.httpClient, fsys)
+		// the sub-source's own HTTP settings take precedence
+		client, err := httpClientFor(subSource)
+		if err != nil {
+			return nil, &fs.PathError{
+				Op: "open", Path: name,
+				Err: fmt.Errorf("http client for %s: %w", u.String(), err),
+			}
+		}
+		if client == nil {
+			client = f.httpClient
+		}
+		fsys = fsimpl.WithHTTPClientFS(client, fsys)
 
 		f, err := fsys.Open(base)
 		if err != nil {
@@ -163,6 +214,7 @@ func (f *mergeFS) Open(name string) (fs.File, error) {
 	}
 
 	return &mergeFile{
+		parsers:  parsers.RegistryFromContext(f.ctx),
 		name:     name,
 		subFiles: subFiles,
 		modTime:  modTime,
@@ -174,7 +226,29 @@ type subFile struct {
 	contentType string
 }
 
+// contentFile is an fs.File for content that has already been read
+type contentFile struct {
+	fi fs.FileInfo
+	r  *bytes.Reader
+}
+
+var _ fs.File = (*contentFile)(nil)
+
+func newContentFile(name string, fc *content) *contentFile {
+	return &contentFile{
+		fi: FileInfo(name, int64(len(fc.b)), 0o444, time.Time{}, fc.contentType),
+		r:  bytes.NewReader(fc.b),
+	}
+}
+
+func (f *contentFile) Stat() (fs.FileInfo, error) { return f.fi, nil }
+
+func (f *contentFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+
+func (f *contentFile) Close() error { return nil }
+
 type mergeFile struct {
+	parsers  *parsers.Registry
 	name     string
 	merged   io.Reader // the file's contents, post-merge - buffered here to enable partial reads
 	fi       fs.FileInfo
@@ -248,7 +322,7 @@ func (f *mergeFile) readSubFile(sf subFile) (map[string]any, error) {
 
 	// if we haven't been given a content type hint, guess the normal way
 	if sf.contentType == "" {
-		sf.contentType = fsimpl.ContentType(fi)
+		sf.contentType = contentType(f.parsers, fi)
 	}
 
 	b, err := io.ReadAll(sf)
@@ -256,7 +330,7 @@ func (f *mergeFile) readSubFile(sf subFile) (map[string]any, error) {
 		return nil, fmt.Errorf("readAll: %w", err)
 	}
 
-	sfData, err := parseMap(sf.contentType, string(b))
+	sfData, err := parseMap(f.parsers, sf.contentType, string(b))
 	if err != nil {
 		return nil, fmt.Errorf("parsing map with content type %s: %w", sf.contentType, err)
 	}
@@ -280,8 +354,8 @@ func mergeData(data []map[string]any) ([]byte, error) {
 	return []byte(s), nil
 }
 
-func parseMap(mimeType, data string) (map[string]any, error) {
-	datum, err := parsers.ParseData(mimeType, data)
+func parseMap(r *parsers.Registry, mimeType, data string) (map[string]any, error) {
+	datum, err := r.ParseData(mimeType, data)
 	if err != nil {
 		return nil, fmt.Errorf("parseData: %w", err)
 	}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -16,6 +15,7 @@ import (
 	"github.com/hairyhenderson/go-fsimpl"
 	"github.com/hairyhenderson/gomplate/v5/internal/config"
 	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+	"github.com/hairyhenderson/gomplate/v5/internal/parsers"
 )
 
 const osWindows = "windows"
@@ -38,7 +38,8 @@ type DataSourceReader interface {
 	// URL. If the alias is not a valid URL, an error is returned.
 	//
 	// Returned content is cached, so subsequent calls with the same alias and
-	// arguments will return the same content.
+	// arguments will return the same content. Concurrent calls with the same
+	// alias and arguments result in only one read.
 	ReadSource(ctx context.Context, alias string, args ...string) (string, []byte, error)
 
 	// contains registry
@@ -46,7 +47,14 @@ type DataSourceReader interface {
 }
 
 type dsReader struct {
-	cache map[string]*content
+	// persistent cache for remote datasources - nil disables it
+	disk  *diskCache
+	cache contentCache
+
+	// reads are recorded into the fixture, or replayed from it when replay is
+	// set
+	fixture *Fixture
+	replay  bool
 
 	Registry
 }
@@ -61,6 +69,37 @@ func NewSourceReader(reg Registry) DataSourceReader {
 	return &dsReader{Registry: reg}
 }
 
+// SourceReaderOptions - options for NewSourceReaderWithOptions
+type SourceReaderOptions struct {
+	// CacheObserver is notified of every in-memory cache lookup (useful for
+	// metrics)
+	CacheObserver CacheObserver
+
+	// CacheDir - the directory for the persistent cache of remote
+	// datasources. Only datasources with a CacheTTL are cached. Defaults to a
+	// "gomplate" directory in the user's cache directory.
+	CacheDir string
+
+	// Offline - read remote datasources only from the persistent cache
+	Offline bool
+
+	// Fixture - successful reads are recorded into this fixture, or, when
+	// Replay is set, served only from it
+	Fixture *Fixture
+	Replay  bool
+}
+
+// NewSourceReaderWithOptions - like NewSourceReader, but with extra options
+func NewSourceReaderWithOptions(reg Registry, opts SourceReaderOptions) DataSourceReader {
+	return &dsReader{
+		Registry: reg,
+		cache:    contentCache{observer: opts.CacheObserver},
+		disk:     &diskCache{dir: opts.CacheDir, offline: opts.Offline},
+		fixture:  opts.Fixture,
+		replay:   opts.Replay && opts.Fixture != nil,
+	}
+}
+
 func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string) (string, []byte, error) {
 	source, ok := d.Lookup(alias)
 	if !ok {
@@ -69,6 +108,11 @@ func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string)
 			return "", nil, fmt.Errorf("undefined datasource '%s': %w", alias, err)
 		}
 
+		// templates can't run commands
+		if IsExecURL(srcURL) {
+			return "", nil, fmt.Errorf("undefined datasource '%s': exec datasources can't be defined in templates", alias)
+		}
+
 		d.Register(alias, config.DataSource{URL: srcURL})
 
 		// repeat the lookup now that it's registered - we shouldn't just use
@@ -76,44 +120,69 @@ func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string)
 		source, _ = d.Lookup(alias)
 	}
 
-	if d.cache == nil {
-		d.cache = make(map[string]*content)
-	}
 	cacheKey := alias
 	for _, v := range args {
 		cacheKey += v
 	}
-	cached, ok := d.cache[cacheKey]
-	if ok {
-		return cached.contentType, cached.b, nil
-	}
 
-	arg := ""
-	if len(args) > 0 {
-		arg = args[0]
-	}
-	u, err := resolveURL(*source.URL, arg)
-	if err != nil {
-		return "", nil, err
-	}
+	fc, err := d.cache.get(ctx, cacheKey, func() (*content, error) {
+		if d.replay {
+			return d.fixture.replay(alias, args)
+		}
+
+		arg := ""
+		if len(args) > 0 {
+			arg = args[0]
+		}
+
+		// for HTTP requests with a body, the argument is the body
+		if isHTTPRequest(source.URL, source) {
+			if arg != "" {
+				source.Body = arg
+			}
+			arg = ""
+		}
+
+		u, err := resolveURL(*source.URL, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		// commands can only be run by exec datasources defined outside of
+		// templates
+		if IsExecURL(u) && !IsExecURL(source.URL) {
+			return nil, fmt.Errorf("couldn't read datasource '%s': argument %q can't run a command", alias, arg)
+		}
 
-	fc, err := d.readFileContent(ctx, u, source.Header)
+		fc, err := d.readContent(ctx, u, source)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read datasource '%s' (%s): %w", alias, u, err)
+		}
+
+		if d.fixture != nil {
+			d.fixture.record(alias, args, u, fc)
+		}
+
+		return fc, nil
+	})
 	if err != nil {
-		return "", nil, fmt.Errorf("couldn't read datasource '%s' (%s): %w", alias, u, err)
+		return "", nil, err
 	}
-	d.cache[cacheKey] = fc
 
 	return fc.contentType, fc.b, nil
 }
 
+// removeQueryParam returns a copy of the URL without the given query parameter.
+// The URL may belong to a registered datasource, so it must not be modified.
 func removeQueryParam(u *url.URL, key string) *url.URL {
-	q := u.Query()
+	c := *u
+	q := c.Query()
 	q.Del(key)
-	u.RawQuery = q.Encode()
-	return u
+	c.RawQuery = q.Encode()
+	return &c
 }
 
-func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Header) (*content, error) {
+func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, source config.DataSource) (*content, error) {
 	// possible type hint in the type query param. Contrary to spec, we allow
 	// unescaped '+' characters to make it simpler to provide types like
 	// "application/array+json"
@@ -132,6 +201,8 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 		return nil, fmt.Errorf("fsys for path %v: %w", u, err)
 	}
 
+	fsys = httpRequestFSFor(u, source, fsys)
+
 	// need to support absolute paths on local filesystem too
 	// TODO: this is a hack, probably fix this?
 	if u.Scheme == "file" && runtime.GOOS != osWindows {
@@ -139,8 +210,22 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 	}
 
 	fsys = fsimpl.WithContextFS(ctx, fsys)
-	fsys = fsimpl.WithHeaderFS(hdr, fsys)
+	fsys = fsimpl.WithHeaderFS(source.Header, fsys)
+
+	client, err := httpClientFor(source)
+	if err != nil {
+		return nil, fmt.Errorf("http client for %v: %w", u, err)
+	}
+	fsys = fsimpl.WithHTTPClientFS(client, fsys)
 	fsys = WithDataSourceRegistryFS(d.Registry, fsys)
+	fsys = withContentReaderFS(func(ctx context.Context, u *url.URL, part config.DataSource) (*content, error) {
+		// parts without their own HTTP settings inherit the merge datasource's
+		if part.Retries <= 0 && part.Timeout <= 0 && part.TLS == nil {
+			part.Retries, part.Timeout, part.Backoff, part.TLS = source.Retries, source.Timeout, source.Backoff, source.TLS
+		}
+
+		return d.readContent(ctx, u, part)
+	}, fsys)
 
 	f, err := fsys.Open(fname)
 	if err != nil {
@@ -154,7 +239,7 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 	}
 
 	if mimeType == "" {
-		mimeType = fsimpl.ContentType(fi)
+		mimeType = contentType(parsers.RegistryFromContext(ctx), fi)
 	}
 
 	var data []byte
@@ -191,6 +276,26 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 	return &content{contentType: mimeType, b: data}, nil
 }
 
+// contentType returns the content type of the file. A type provided by the
+// filesystem (e.g. from an HTTP Content-Type header) is preferred, then types
+// for extensions registered by custom parsers, then types for extensions
+// gomplate knows about, and then the standard types for the file's extension.
+func contentType(r *parsers.Registry, fi fs.FileInfo) string {
+	if cf, ok := fi.(interface{ ContentType() string }); ok && cf.ContentType() != "" {
+		return cf.ContentType()
+	}
+
+	if ct := r.MimeTypeByExtension(path.Ext(fi.Name())); ct != "" {
+		return ct
+	}
+
+	if ct := iohelpers.MimeTypeByExtension(path.Ext(fi.Name())); ct != "" {
+		return ct
+	}
+
+	return fsimpl.ContentType(fi)
+}
+
 // resolveURL parses the relative URL rel against base, and returns the
 // resolved URL. Differs from url.ResolveReference in that query parameters are
 // added. In case of duplicates, params from rel are used.
@@ -221,6 +326,12 @@ func resolveURL(base url.URL, rel string) (*url.URL, error) {
 		if strings.HasPrefix(rel, "//") {
 			rel = "." + rel
 		}
+	case "sqlite":
+		// the subpath for sqlite URLs is the SQL query
+		return sqliteQueryURL(base, rel), nil
+	case "exec":
+		// the subpath for exec URLs is more arguments for the command
+		return execArgsURL(base, rel), nil
 	case "aws+sm":
 		// aws+sm URLs may be opaque, so resolution needs to be handled
 		// differently
@@ -250,6 +361,12 @@ func resolveURL(base url.URL, rel string) (*url.URL, error) {
 		return nil, err
 	}
 
+	// an absolute URL would replace the base entirely, letting arguments read
+	// from (or run) anything at all
+	if relURL.Scheme != "" && relURL.Scheme != base.Scheme {
+		return nil, fmt.Errorf("subpath %q must not change the datasource's scheme %q", rel, base.Scheme)
+	}
+
 	// URL.ResolveReference requires (or assumes, at least) that the base is
 	// absolute. We want to support relative URLs too though, so we need to
 	// correct for that.
@@ -28,6 +28,18 @@ func TestResolveURL(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "http://example.com/a/b/bar.json?n=2&q=1", out.String())
 
+	out, err = resolveURL(*mustParseURL("sqlite:///tmp/inventory.db?query=SELECT+1"), "SELECT * FROM items WHERE qty > 1")
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite:///tmp/inventory.db?query=SELECT+%2A+FROM+items+WHERE+qty+%3E+1", out.String())
+
+	out, err = resolveURL(*mustParseURL("exec:kubectl?arg=get"), "pods  -o json")
+	require.NoError(t, err)
+	assert.Equal(t, "exec:kubectl?arg=get&arg=pods&arg=-o&arg=json", out.String())
+
+	out, err = resolveURL(*mustParseURL("sqlite:inventory.db"), "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite:inventory.db?query=SELECT+1", out.String())
+
 	out, err = resolveURL(*mustParseURL("git+file:///tmp/myrepo"), "//myfile?type=application/json")
 	require.NoError(t, err)
 	assert.Equal(t, "git+file:///tmp/myrepo//myfile?type=application/json", out.String())
@@ -60,6 +72,17 @@ func TestResolveURL(t *testing.T) {
 	_, err = resolveURL(*mustParseURL("git+ssh://git@example.com/foo//bar"), "baz//myfile")
 	require.Error(t, err)
 
+	// absolute URLs can't replace the base's scheme
+	_, err = resolveURL(*mustParseURL("file:///tmp/ex/"), "exec:sh?arg=-c&arg=echo+pwned")
+	require.Error(t, err)
+
+	_, err = resolveURL(*mustParseURL("https://example.com/"), "file:///etc/passwd")
+	require.Error(t, err)
+
+	out, err = resolveURL(*mustParseURL("https://example.com/"), "https://example.com/foo.json")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/foo.json", out.String())
+
 	// relative base URLs must remain relative
 	out, err = resolveURL(*mustParseURL("tmp/foo.json"), "")
 	require.NoError(t, err)
@@ -154,6 +177,12 @@ func TestReadFileContent(t *testing.T) {
 		"dir/1.yaml":        &fstest.MapFile{Data: []byte(`foo: bar`)},
 		"dir/2.yaml":        &fstest.MapFile{Data: []byte(`baz: qux`)},
 		"dir/sub/sub1.yaml": &fstest.MapFile{Data: []byte(`quux: corge`)},
+		"terraform.tfvars":  &fstest.MapFile{Data: []byte(`foo = "bar"`)},
+		"app.properties":    &fstest.MapFile{Data: []byte(`foo=bar`)},
+		"events.ndjson":     &fstest.MapFile{Data: []byte(`{"foo":"bar"}`)},
+		"settings.jsonc":    &fstest.MapFile{Data: []byte(`{"foo":"bar"}`)},
+		"Info.plist":        &fstest.MapFile{Data: []byte(`<plist version="1.0"><dict/></plist>`)},
+		"post.md":           &fstest.MapFile{Data: []byte("---\ntitle: Hello\n---\n")},
 	})
 
 	fsp := fsimpl.NewMux()
@@ -165,17 +194,47 @@ func TestReadFileContent(t *testing.T) {
 	reg := NewRegistry()
 	sr := &dsReader{Registry: reg}
 
-	fc, err := sr.readFileContent(ctx, mustParseURL("file:///foo.json"), nil)
+	fc, err := sr.readFileContent(ctx, mustParseURL("file:///foo.json"), config.DataSource{})
 	require.NoError(t, err)
 	assert.JSONEq(t, `{"foo": "bar"}`, string(fc.b))
 
-	fc, err = sr.readFileContent(ctx, mustParseURL("dir/"), nil)
+	fc, err = sr.readFileContent(ctx, mustParseURL("dir/"), config.DataSource{})
 	require.NoError(t, err)
 	assert.JSONEq(t, `["1.yaml", "2.yaml", "sub"]`, string(fc.b))
 
-	fc, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/foo.json"), nil)
+	fc, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/foo.json"), config.DataSource{})
 	require.NoError(t, err)
 	assert.JSONEq(t, `{"foo": "bar"}`, string(fc.b))
+
+	// extensions that aren't in the standard MIME type database
+	fc, err = sr.readFileContent(ctx, mustParseURL("terraform.tfvars"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.HCLMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("app.properties"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.PropertiesMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("events.ndjson"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NDJSONMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("settings.jsonc"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONCMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("Info.plist"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.PlistMimetype, fc.contentType)
+
+	// .md files are plain text unless the type is set
+	fc, err = sr.readFileContent(ctx, mustParseURL("post.md"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.TextMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("post.md?type=text/markdown"), config.DataSource{})
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.MarkdownMimetype, fc.contentType)
 }
 
 func TestDatasource(t *testing.T) {
@@ -2,6 +2,7 @@ package datafs
 
 import (
 	"net/http"
+	"net/url"
 	"sort"
 	"sync"
 
@@ -20,6 +21,11 @@ type Registry interface {
 	// Add extra headers not attached to a pre-defined datasource. These can be
 	// used by datasources registered at runtime.
 	AddExtraHeader(alias string, hdr http.Header)
+
+	// Override the URL of a datasource, whether it's already registered or
+	// registered later (e.g. at runtime). Nothing else about the datasource
+	// is changed.
+	AddURLOverride(alias string, u *url.URL)
 }
 
 func NewRegistry() Registry {
@@ -27,6 +33,7 @@ func NewRegistry() Registry {
 		RWMutex:      &sync.RWMutex{},
 		m:            map[string]config.DataSource{},
 		extraHeaders: map[string]http.Header{},
+		urlOverrides: map[string]*url.URL{},
 	}
 }
 
@@ -34,6 +41,7 @@ type dsRegistry struct {
 	*sync.RWMutex
 	m            map[string]config.DataSource
 	extraHeaders map[string]http.Header
+	urlOverrides map[string]*url.URL
 }
 
 // Register a datasource
@@ -47,6 +55,10 @@ func (r *dsRegistry) Register(alias string, ds config.DataSource) {
 		ds.Header = hdr
 	}
 
+	if u, ok := r.urlOverrides[alias]; ok {
+		ds.URL = u
+	}
+
 	r.m[alias] = ds
 }
 
@@ -86,3 +98,18 @@ func (r *dsRegistry) AddExtraHeader(alias string, hdr http.Header) {
 
 	r.extraHeaders[alias] = hdr
 }
+
+// AddURLOverride overrides the URL of the datasource with the given alias. If
+// the datasource is already registered its URL is replaced, otherwise the URL
+// is replaced when it's registered.
+func (r *dsRegistry) AddURLOverride(alias string, u *url.URL) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.urlOverrides[alias] = u
+
+	if ds, ok := r.m[alias]; ok {
+		ds.URL = u
+		r.m[alias] = ds
+	}
+}
@@ -2,6 +2,7 @@ package datafs
 
 import (
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/hairyhenderson/gomplate/v5/internal/config"
@@ -46,3 +47,30 @@ func TestDefaultRegistry_AddExtraHeader(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, hdr, ds.Header)
 }
+
+func TestDefaultRegistry_AddURLOverride(t *testing.T) {
+	reg := NewRegistry()
+	hdr := http.Header{"foo": {"bar"}}
+	override := &url.URL{Scheme: "file", Path: "/tmp/secrets.json"}
+
+	// already registered
+	reg.Register("foo", config.DataSource{URL: &url.URL{Scheme: "vault", Path: "/secret/foo"}, Header: hdr})
+	reg.AddURLOverride("foo", override)
+
+	ds, ok := reg.Lookup("foo")
+	require.True(t, ok)
+	require.Equal(t, config.DataSource{URL: override, Header: hdr}, ds)
+
+	// registered later, e.g. with defineDatasource
+	reg.AddURLOverride("bar", override)
+	reg.Register("bar", config.DataSource{URL: &url.URL{Scheme: "https", Host: "example.com"}, Header: hdr})
+
+	ds, ok = reg.Lookup("bar")
+	require.True(t, ok)
+	require.Equal(t, config.DataSource{URL: override, Header: hdr}, ds)
+
+	// overrides don't register anything themselves
+	reg.AddURLOverride("baz", override)
+	_, ok = reg.Lookup("baz")
+	require.False(t, ok)
+}
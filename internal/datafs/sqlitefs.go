@@ -0,0 +1,179 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+
+	// register the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// newSQLiteFS returns a filesystem for querying a SQLite database. The URL's
+// path names the database file (an opaque URL like "sqlite:data.db" can be
+// used for paths relative to the working directory), and the "query" parameter
+// holds the SQL query. Opening "." runs the query, and the file contains the
+// resulting rows as a JSON array of objects.
+//
+// The database is opened read-only.
+func newSQLiteFS(u *url.URL) (fs.FS, error) {
+	if u.Scheme != "sqlite" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	dbPath := u.Opaque
+	if dbPath == "" {
+		dbPath = u.Path
+
+		// on Windows, "/C:/foo.db" should be "C:/foo.db"
+		if len(dbPath) > 2 && dbPath[0] == '/' && dbPath[2] == ':' {
+			dbPath = dbPath[1:]
+		}
+	}
+
+	if dbPath == "" || dbPath == "/" {
+		return nil, fmt.Errorf("no database file given in URL %q", u.Redacted())
+	}
+
+	return &sqliteFS{
+		ctx:    context.Background(),
+		dbPath: filepath.FromSlash(dbPath),
+		query:  u.Query().Get("query"),
+	}, nil
+}
+
+type sqliteFS struct {
+	ctx    context.Context
+	dbPath string
+	query  string
+}
+
+//nolint:gochecknoglobals
+var SQLiteFS = fsimpl.FSProviderFunc(newSQLiteFS, "sqlite")
+
+var (
+	_ fs.FS         = (*sqliteFS)(nil)
+	_ withContexter = (*sqliteFS)(nil)
+)
+
+func (f *sqliteFS) WithContext(ctx context.Context) fs.FS {
+	if ctx == nil {
+		return f
+	}
+
+	fsys := *f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *sqliteFS) Open(name string) (fs.File, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if f.query == "" {
+		return nil, &fs.PathError{
+			Op: "open", Path: f.dbPath,
+			Err: fmt.Errorf("no query given - set the query parameter or provide it as a datasource argument"),
+		}
+	}
+
+	b, err := f.runQuery()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: f.dbPath, Err: err}
+	}
+
+	return &sqliteFile{
+		fi:   FileInfo(filepath.Base(f.dbPath), int64(len(b)), 0o444, time.Time{}, iohelpers.JSONArrayMimetype),
+		body: bytes.NewReader(b),
+	}, nil
+}
+
+// runQuery runs the query and marshals the resulting rows as JSON
+func (f *sqliteFS) runQuery() ([]byte, error) {
+	// mode=ro also prevents a missing database file from being created
+	dsn := "file:" + (&url.URL{Path: filepath.ToSlash(f.dbPath)}).EscapedPath() + "?mode=ro"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(f.ctx, f.query)
+	if err != nil {
+		return nil, fmt.Errorf("query %q: %w", f.query, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("columns: %w", err)
+	}
+
+	out := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			row[col] = values[i]
+		}
+		out = append(out, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query %q: %w", f.query, err)
+	}
+
+	return json.Marshal(out)
+}
+
+type sqliteFile struct {
+	fi   fs.FileInfo
+	body io.Reader
+}
+
+var _ fs.File = (*sqliteFile)(nil)
+
+func (f *sqliteFile) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f *sqliteFile) Read(p []byte) (int, error) {
+	return f.body.Read(p)
+}
+
+func (f *sqliteFile) Close() error {
+	return nil
+}
+
+// sqliteQueryURL sets the query parameter of a sqlite URL - this is how
+// datasource arguments are handled for the sqlite scheme
+func sqliteQueryURL(base url.URL, query string) *url.URL {
+	q := base.Query()
+	q.Set("query", strings.TrimSpace(query))
+	base.RawQuery = q.Encode()
+
+	return &base
+}
@@ -0,0 +1,137 @@
+package datafs
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v5/internal/config"
+	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSQLiteDB(t *testing.T) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "inventory.db")
+
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE items (name TEXT, qty INTEGER, price REAL, note TEXT);
+		INSERT INTO items VALUES ('widget', 3, 1.5, NULL), ('gadget', 10, 2.25, 'new');`)
+	require.NoError(t, err)
+
+	return dbPath
+}
+
+func sqliteURL(dbPath, query string) *url.URL {
+	u := &url.URL{Scheme: "sqlite", Path: filepath.ToSlash(dbPath)}
+	if u.Path[0] != '/' {
+		u.Path = "/" + u.Path
+	}
+
+	return sqliteQueryURL(*u, query)
+}
+
+func TestSQLiteFS(t *testing.T) {
+	dbPath := setupSQLiteDB(t)
+
+	fsys, err := newSQLiteFS(sqliteURL(dbPath, "SELECT * FROM items ORDER BY name"))
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "gadget", "qty": 10, "price": 2.25, "note": "new"},
+		{"name": "widget", "qty": 3, "price": 1.5, "note": null}
+	]`, string(b))
+
+	fi, err := fs.Stat(fsys, ".")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONArrayMimetype, fi.(interface{ ContentType() string }).ContentType())
+
+	// no rows is an empty array
+	fsys, err = newSQLiteFS(sqliteURL(dbPath, "SELECT * FROM items WHERE qty > 100"))
+	require.NoError(t, err)
+
+	b, err = fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(b))
+
+	// the context is used for queries
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fsys, err = newSQLiteFS(sqliteURL(dbPath, "SELECT * FROM items"))
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsimpl.WithContextFS(ctx, fsys), ".")
+	require.Error(t, err)
+
+	// only "." can be opened
+	_, err = fsys.Open("items")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// a query is required
+	fsys, err = newSQLiteFS(&url.URL{Scheme: "sqlite", Path: "/" + filepath.ToSlash(dbPath)})
+	require.NoError(t, err)
+
+	_, err = fsys.Open(".")
+	require.Error(t, err)
+
+	// the database is read-only
+	fsys, err = newSQLiteFS(sqliteURL(dbPath, "DELETE FROM items"))
+	require.NoError(t, err)
+
+	_, err = fsys.Open(".")
+	require.Error(t, err)
+
+	// missing databases aren't created
+	missing := filepath.Join(filepath.Dir(dbPath), "missing.db")
+	fsys, err = newSQLiteFS(sqliteURL(missing, "SELECT 1"))
+	require.NoError(t, err)
+
+	_, err = fsys.Open(".")
+	require.Error(t, err)
+	assert.NoFileExists(t, missing)
+
+	_, err = newSQLiteFS(&url.URL{Scheme: "sqlite"})
+	require.Error(t, err)
+
+	_, err = newSQLiteFS(&url.URL{Scheme: "file", Path: "/foo.db"})
+	require.Error(t, err)
+}
+
+func TestReadSQLiteDataSource(t *testing.T) {
+	dbPath := setupSQLiteDB(t)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(SQLiteFS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	reg := NewRegistry()
+	u := sqliteURL(dbPath, "")
+	u.RawQuery = ""
+	reg.Register("inv", config.DataSource{URL: u})
+
+	sr := NewSourceReader(reg)
+
+	ct, b, err := sr.ReadSource(ctx, "inv", "SELECT name FROM items WHERE qty > 5")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONArrayMimetype, ct)
+	assert.JSONEq(t, `[{"name": "gadget"}]`, string(b))
+
+	// the query can also be given in the URL
+	reg.Register("count", config.DataSource{URL: sqliteURL(dbPath, "SELECT count(*) AS n FROM items")})
+
+	_, b, err = sr.ReadSource(ctx, "count")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"n": 2}]`, string(b))
+}
@@ -6,6 +6,14 @@ import (
 	"log/slog"
 )
 
+// Functions - deprecated template functions, mapped to the message to show
+// when they're used
+var Functions = map[string]string{
+	"crypto.DecryptAES":      "crypto.DecryptAES is deprecated - use crypto.AESDecrypt instead",
+	"crypto.DecryptAESBytes": "crypto.DecryptAESBytes is deprecated - use crypto.AESDecryptBytes instead",
+	"crypto.EncryptAES":      "crypto.EncryptAES is deprecated - use crypto.AESEncrypt instead",
+}
+
 // WarnDeprecated - use this to warn about deprecated template functions or
 // datasources
 func WarnDeprecated(ctx context.Context, msg string) {
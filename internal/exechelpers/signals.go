@@ -0,0 +1,57 @@
+package exechelpers
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+//nolint:gochecknoglobals
+var (
+	sigMu sync.Mutex
+	procs = map[*os.Process]struct{}{}
+	sigs  chan os.Signal
+)
+
+// ForwardSignals passes all signals received by gomplate on to the process,
+// until the returned stop function is called (typically once the process has
+// exited). A single signal handler is shared by all processes, and it's only
+// installed while at least one process is running.
+func ForwardSignals(p *os.Process) (stop func()) {
+	sigMu.Lock()
+	defer sigMu.Unlock()
+
+	if len(procs) == 0 {
+		sigs = make(chan os.Signal, 1)
+		signal.Notify(sigs)
+
+		go forwardSignals(sigs)
+	}
+
+	procs[p] = struct{}{}
+
+	return sync.OnceFunc(func() {
+		sigMu.Lock()
+		defer sigMu.Unlock()
+
+		delete(procs, p)
+
+		if len(procs) == 0 {
+			// no signals are delivered once Stop returns, so it's safe to
+			// close the channel
+			signal.Stop(sigs)
+			close(sigs)
+			sigs = nil
+		}
+	})
+}
+
+func forwardSignals(sigs <-chan os.Signal) {
+	for sig := range sigs {
+		sigMu.Lock()
+		for p := range procs {
+			_ = p.Signal(sig)
+		}
+		sigMu.Unlock()
+	}
+}
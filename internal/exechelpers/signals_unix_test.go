@@ -0,0 +1,59 @@
+//go:build !windows
+
+package exechelpers
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardSignals(t *testing.T) {
+	// each command exits cleanly once it gets SIGUSR1
+	start := func() (*exec.Cmd, func()) {
+		c := exec.Command("sh", "-c", `trap 'exit 0' USR1; echo ready; while :; do sleep 0.01; done`)
+		out, err := c.StdoutPipe()
+		require.NoError(t, err)
+		require.NoError(t, c.Start())
+
+		stop := ForwardSignals(c.Process)
+
+		// wait for the trap to be set
+		_, err = bufio.NewReader(out).ReadString('\n')
+		require.NoError(t, err)
+
+		return c, stop
+	}
+
+	c1, stop1 := start()
+	c2, stop2 := start()
+
+	// SIGUSR1 is used so that a failure to handle the signal doesn't kill the
+	// test binary
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	wg := sync.WaitGroup{}
+	for _, c := range []*exec.Cmd{c1, c2} {
+		wg.Go(func() {
+			assert.NoError(t, c.Wait())
+		})
+	}
+	wg.Wait()
+
+	stop1()
+	stop2()
+	stop2()
+
+	// the handler is removed once no processes are left
+	sigMu.Lock()
+	defer sigMu.Unlock()
+
+	assert.Empty(t, procs)
+	assert.Nil(t, sigs)
+}
@@ -500,7 +500,7 @@ func (f *CryptoFuncs) AESDecryptBytes(key string, args ...any) ([]byte, error) {
 //
 // Deprecated: Use AESEncrypt instead.
 func (f *CryptoFuncs) EncryptAES(key string, args ...any) ([]byte, error) {
-	deprecated.WarnDeprecated(f.ctx, "crypto.EncryptAES is deprecated - use crypto.AESEncrypt instead")
+	deprecated.WarnDeprecated(f.ctx, deprecated.Functions["crypto.EncryptAES"])
 	return f.AESEncrypt(key, args...)
 }
 
@@ -508,7 +508,7 @@ func (f *CryptoFuncs) EncryptAES(key string, args ...any) ([]byte, error) {
 //
 // Deprecated: Use AESDecrypt instead.
 func (f *CryptoFuncs) DecryptAES(key string, args ...any) (string, error) {
-	deprecated.WarnDeprecated(f.ctx, "crypto.DecryptAES is deprecated - use crypto.AESDecrypt instead")
+	deprecated.WarnDeprecated(f.ctx, deprecated.Functions["crypto.DecryptAES"])
 	return f.AESDecrypt(key, args...)
 }
 
@@ -516,7 +516,7 @@ func (f *CryptoFuncs) DecryptAES(key string, args ...any) (string, error) {
 //
 // Deprecated: Use AESDecryptBytes instead.
 func (f *CryptoFuncs) DecryptAESBytes(key string, args ...any) ([]byte, error) {
-	deprecated.WarnDeprecated(f.ctx, "crypto.DecryptAESBytes is deprecated - use crypto.AESDecryptBytes instead")
+	deprecated.WarnDeprecated(f.ctx, deprecated.Functions["crypto.DecryptAESBytes"])
 	return f.AESDecryptBytes(key, args...)
 }
 
@@ -2,6 +2,8 @@ package funcs
 
 import (
 	"context"
+	"fmt"
+	"unicode/utf8"
 
 	"github.com/hairyhenderson/gomplate/v5/conv"
 	"github.com/hairyhenderson/gomplate/v5/internal/parsers"
@@ -17,19 +19,36 @@ func CreateDataFuncs(ctx context.Context) map[string]any {
 
 	f["json"] = ns.JSON
 	f["jsonArray"] = ns.JSONArray
+	f["ndjson"] = ns.NDJSON
+	f["json5"] = ns.JSON5
 	f["yaml"] = ns.YAML
 	f["yamlArray"] = ns.YAMLArray
+	f["yamlDocuments"] = ns.YAMLDocuments
 	f["toml"] = ns.TOML
 	f["csv"] = ns.CSV
 	f["csvByRow"] = ns.CSVByRow
 	f["csvByColumn"] = ns.CSVByColumn
+	f["csvTyped"] = ns.CSVTyped
 	f["cue"] = ns.CUE
+	f["xml"] = ns.XML
+	f["hcl"] = ns.HCL
+	f["ini"] = ns.INI
+	f["properties"] = ns.Properties
+	f["plist"] = ns.Plist
+	f["frontMatter"] = ns.FrontMatter
 	f["toJSON"] = ns.ToJSON
 	f["toJSONPretty"] = ns.ToJSONPretty
+	f["toNDJSON"] = ns.ToNDJSON
 	f["toYAML"] = ns.ToYAML
+	f["toYAMLDocuments"] = ns.ToYAMLDocuments
 	f["toTOML"] = ns.ToTOML
 	f["toCSV"] = ns.ToCSV
 	f["toCUE"] = ns.ToCUE
+	f["toXML"] = ns.ToXML
+	f["toHCL"] = ns.ToHCL
+	f["toINI"] = ns.ToINI
+	f["toProperties"] = ns.ToProperties
+	f["toPlist"] = ns.ToPlist
 	return f
 }
 
@@ -48,6 +67,16 @@ func (f *DataFuncs) JSONArray(in any) ([]any, error) {
 	return parsers.JSONArray(conv.ToString(in))
 }
 
+// NDJSON -
+func (f *DataFuncs) NDJSON(in any) ([]any, error) {
+	return parsers.NDJSON(conv.ToString(in))
+}
+
+// JSON5 -
+func (f *DataFuncs) JSON5(in any) (any, error) {
+	return parsers.JSON5(conv.ToString(in))
+}
+
 // YAML -
 func (f *DataFuncs) YAML(in any) (map[string]any, error) {
 	return parsers.YAML(conv.ToString(in))
@@ -58,6 +87,11 @@ func (f *DataFuncs) YAMLArray(in any) ([]any, error) {
 	return parsers.YAMLArray(conv.ToString(in))
 }
 
+// YAMLDocuments -
+func (f *DataFuncs) YAMLDocuments(in any) ([]any, error) {
+	return parsers.YAMLDocuments(conv.ToString(in))
+}
+
 // TOML -
 func (f *DataFuncs) TOML(in any) (any, error) {
 	return parsers.TOML(conv.ToString(in))
@@ -78,6 +112,66 @@ func (f *DataFuncs) CSVByColumn(args ...string) (cols map[string][]string, err e
 	return parsers.CSVByColumn(args...)
 }
 
+// CSVTyped -
+func (f *DataFuncs) CSVTyped(args ...any) ([]map[string]any, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("expected 1 or 2 args, got %d", len(args))
+	}
+
+	opts := parsers.CSVOptions{}
+	if len(args) == 2 {
+		m, ok := args[0].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected options to be a map[string]any, got %T", args[0])
+		}
+
+		var err error
+		opts, err = csvTypedOptions(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parsers.CSVTyped(conv.ToString(args[len(args)-1]), opts)
+}
+
+func csvTypedOptions(m map[string]any) (parsers.CSVOptions, error) {
+	opts := parsers.CSVOptions{}
+
+	for k, v := range m {
+		switch k {
+		case "delimiter", "quote":
+			s := conv.ToString(v)
+			if utf8.RuneCountInString(s) != 1 {
+				return opts, fmt.Errorf("option %q must be a single character, got %q", k, s)
+			}
+
+			r, _ := utf8.DecodeRuneInString(s)
+			if k == "delimiter" {
+				opts.Delimiter = r
+			} else {
+				opts.Quote = r
+			}
+		case "header":
+			opts.NoHeader = !conv.ToBool(v)
+		case "types":
+			types, ok := v.(map[string]any)
+			if !ok {
+				return opts, fmt.Errorf("option \"types\" must be a map[string]any, got %T", v)
+			}
+
+			opts.Types = make(map[string]string, len(types))
+			for col, typ := range types {
+				opts.Types[col] = conv.ToString(typ)
+			}
+		default:
+			return opts, fmt.Errorf("unknown option %q", k)
+		}
+	}
+
+	return opts, nil
+}
+
 // CUE -
 func (f *DataFuncs) CUE(in any) (any, error) {
 	return parsers.CUE(conv.ToString(in))
@@ -103,12 +197,89 @@ func (f *DataFuncs) ToJSONPretty(indent string, in any) (string, error) {
 	return parsers.ToJSONPretty(indent, in)
 }
 
+// ToNDJSON -
+func (f *DataFuncs) ToNDJSON(in any) (string, error) {
+	return parsers.ToNDJSON(in)
+}
+
 // ToYAML -
 func (f *DataFuncs) ToYAML(in any) (string, error) {
 	return parsers.ToYAML(in)
 }
 
+// ToYAMLDocuments -
+func (f *DataFuncs) ToYAMLDocuments(in any) (string, error) {
+	return parsers.ToYAMLDocuments(in)
+}
+
 // ToTOML -
 func (f *DataFuncs) ToTOML(in any) (string, error) {
 	return parsers.ToTOML(in)
 }
+
+// XML -
+func (f *DataFuncs) XML(in any) (map[string]any, error) {
+	return parsers.XML(conv.ToString(in))
+}
+
+// ToXML -
+func (f *DataFuncs) ToXML(in any) (string, error) {
+	return parsers.ToXML(in)
+}
+
+// HCL -
+func (f *DataFuncs) HCL(in any) (map[string]any, error) {
+	return parsers.HCL(conv.ToString(in))
+}
+
+// ToHCL -
+func (f *DataFuncs) ToHCL(in any) (string, error) {
+	return parsers.ToHCL(in)
+}
+
+// INI -
+func (f *DataFuncs) INI(in any) (map[string]any, error) {
+	return parsers.INI(conv.ToString(in))
+}
+
+// ToINI -
+func (f *DataFuncs) ToINI(in any) (string, error) {
+	return parsers.ToINI(in)
+}
+
+// Properties -
+func (f *DataFuncs) Properties(in any) (map[string]any, error) {
+	return parsers.Properties(conv.ToString(in))
+}
+
+// ToProperties -
+func (f *DataFuncs) ToProperties(in any) (string, error) {
+	return parsers.ToProperties(in)
+}
+
+// Plist -
+func (f *DataFuncs) Plist(in any) (any, error) {
+	return parsers.Plist(conv.ToString(in))
+}
+
+// FrontMatter -
+func (f *DataFuncs) FrontMatter(in any) (map[string]any, error) {
+	return parsers.FrontMatter(conv.ToString(in))
+}
+
+// ToPlist -
+func (f *DataFuncs) ToPlist(in any) (string, error) {
+	return parsers.ToPlist(in)
+}
+
+// Parse - parses the input as the given MIME type, with a custom parser if
+// one is registered for the type
+func (f *DataFuncs) Parse(mimeType string, in any) (any, error) {
+	return parsers.RegistryFromContext(f.ctx).ParseData(mimeType, conv.ToString(in))
+}
+
+// Encode - encodes the value as the given MIME type, with a custom encoder if
+// one is registered for the type
+func (f *DataFuncs) Encode(mimeType string, in any) (string, error) {
+	return parsers.RegistryFromContext(f.ctx).Encode(mimeType, in)
+}
@@ -3,9 +3,12 @@ package funcs
 import (
 	"context"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/hairyhenderson/gomplate/v5/internal/parsers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateDataFuncs(t *testing.T) {
@@ -24,3 +27,84 @@ func TestCreateDataFuncs(t *testing.T) {
 		})
 	}
 }
+
+func TestCSVTyped(t *testing.T) {
+	t.Parallel()
+
+	d := &DataFuncs{}
+
+	out, err := d.CSVTyped("name,age\nAlice,30\n")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "Alice", "age": 30}}, out)
+
+	out, err = d.CSVTyped(map[string]any{
+		"delimiter": "\t",
+		"quote":     "'",
+		"header":    false,
+		"types":     map[string]any{"B": "string"},
+	}, "'Alice\tB'\t30\n")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"A": "Alice\tB", "B": "30"}}, out)
+
+	_, err = d.CSVTyped()
+	require.Error(t, err)
+
+	_, err = d.CSVTyped("a", "b", "c")
+	require.Error(t, err)
+
+	_, err = d.CSVTyped("not a map", "a\n1\n")
+	require.Error(t, err)
+
+	_, err = d.CSVTyped(map[string]any{"delimiter": ";;"}, "a\n1\n")
+	require.Error(t, err)
+
+	_, err = d.CSVTyped(map[string]any{"types": "a:int"}, "a\n1\n")
+	require.Error(t, err)
+
+	_, err = d.CSVTyped(map[string]any{"bogus": true}, "a\n1\n")
+	require.Error(t, err)
+}
+
+func TestParseAndEncode(t *testing.T) {
+	t.Parallel()
+
+	reg := parsers.NewRegistry(map[string]parsers.Parser{
+		"application/x-upper": {
+			Parse: func(in []byte) (any, error) {
+				return strings.ToUpper(string(in)), nil
+			},
+			Encode: func(in any) ([]byte, error) {
+				return []byte(strings.ToLower(in.(string))), nil
+			},
+		},
+	})
+	d := &DataFuncs{ctx: parsers.ContextWithRegistry(context.Background(), reg)}
+
+	out, err := d.Parse("application/x-upper", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", out)
+
+	s, err := d.Encode("application/x-upper", "HELLO")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", s)
+
+	out, err = d.Parse("application/json", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, out)
+
+	s, err = d.Encode("application/json", map[string]any{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, s)
+
+	_, err = d.Parse("application/x-unknown", "foo")
+	require.Error(t, err)
+
+	_, err = d.Encode("application/x-unknown", "foo")
+	require.Error(t, err)
+
+	// no registry in the context
+	d = &DataFuncs{ctx: context.Background()}
+	s, err = d.Encode("text/csv", [][]string{{"a", "b"}, {"1", "2"}})
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\r\n1,2\r\n", s)
+}
@@ -58,7 +58,7 @@ func (d *dataSourceFuncs) Datasource(alias string, args ...string) (any, error)
 		return nil, err
 	}
 
-	return parsers.ParseData(ct, string(b))
+	return parsers.RegistryFromContext(d.ctx).ParseData(ct, string(b))
 }
 
 // DefineDatasource -
@@ -75,6 +75,11 @@ func (d *dataSourceFuncs) DefineDatasource(alias, value string) (string, error)
 		return "", fmt.Errorf("parse datasource URL: %w", err)
 	}
 
+	// like plugins, commands can only be configured outside of templates
+	if datafs.IsExecURL(srcURL) {
+		return "", fmt.Errorf("exec datasources can not be defined in templates")
+	}
+
 	d.sr.Register(alias, config.DataSource{URL: srcURL})
 	return "", nil
 }
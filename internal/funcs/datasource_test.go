@@ -74,10 +74,28 @@ func TestDatasource(t *testing.T) {
 
 	testObj("json", iohelpers.JSONMimetype, []byte(`{"hello":{"cruel":"world"}}`))
 	testObj("yml", iohelpers.YAMLMimetype, []byte("hello:\n  cruel: world\n"))
+	testObj("xml", iohelpers.XMLMimetype, []byte("<hello><cruel>world</cruel></hello>"))
+	testObj("xml", "text/xml", []byte("<hello>\n  <cruel>world</cruel>\n</hello>\n"))
+	testObj("hcl", iohelpers.HCLMimetype, []byte("hello {\n  cruel = \"world\"\n}\n"))
+	testObj("ini", iohelpers.INIMimetype, []byte("[hello]\ncruel = world\n"))
+	test("properties", iohelpers.PropertiesMimetype, []byte("hello.cruel=world\n"),
+		map[string]any{"hello.cruel": "world"})
 	test("json", iohelpers.JSONMimetype, []byte(`[1, "two", true]`),
 		[]any{1, "two", true})
 	test("yaml", iohelpers.YAMLMimetype, []byte("---\n- 1\n- two\n- true\n"),
 		[]any{1, "two", true})
+	testObj("json5", iohelpers.JSON5Mimetype, []byte("{hello: {cruel: 'world',},}"))
+	testObj("jsonc", iohelpers.JSONCMimetype, []byte("{\"hello\": {\"cruel\": \"world\"} // comment\n}"))
+	testObj("json", iohelpers.JSON5Mimetype, []byte("// a .json file with comments\n{\"hello\": {\"cruel\": \"world\"}}"))
+	test("tsv", iohelpers.TSVMimetype+"%3Btyped=true", []byte("name\tage\nAlice\t30\n"),
+		[]map[string]any{{"name": "Alice", "age": 30}})
+	test("jsonl", iohelpers.NDJSONMimetype, []byte("{\"a\":1}\n{\"b\":2}\n"),
+		[]any{map[string]any{"a": 1}, map[string]any{"b": 2}})
+	testObj("plist", iohelpers.PlistMimetype, []byte(`<plist version="1.0"><dict><key>hello</key><dict><key>cruel</key><string>world</string></dict></dict></plist>`))
+	test("md", iohelpers.MarkdownMimetype, []byte("---\ntitle: Hello\n---\n# Hello\n"),
+		map[string]any{"meta": map[string]any{"title": "Hello"}, "body": "# Hello\n"})
+	test("yaml", iohelpers.YAMLMimetype+"%3Bmultidoc=true", []byte("a: 1\n---\nb: 2\n"),
+		[]any{map[string]any{"a": 1}, map[string]any{"b": 2}})
 
 	d := setup("", nil)
 	actual, err := d.Datasource("foo")
@@ -149,6 +167,26 @@ func TestInclude(t *testing.T) {
 	assert.Equal(t, contents, actual)
 }
 
+func TestExecDatasourcesInTemplates(t *testing.T) {
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.DefaultProvider)
+
+	reg := datafs.NewRegistry()
+	data := &dataSourceFuncs{sr: datafs.NewSourceReader(reg), ctx: ctx}
+
+	// commands can only be run by datasources defined outside of templates,
+	// so they can't be referred to by URL
+	_, err := data.Include("exec:///bin/echo?arg=hi")
+	require.ErrorContains(t, err, "can't be defined in templates")
+
+	_, err = data.Datasource("exec:echo?arg={}&type=application/json")
+	require.ErrorContains(t, err, "can't be defined in templates")
+
+	_, err = data.Datasource("merge:exec:echo?arg={}&type=application/json|foo.json")
+	require.ErrorContains(t, err, "can't be defined in templates")
+
+	assert.Empty(t, data.ListDatasources())
+}
+
 func TestDefineDatasource(t *testing.T) {
 	reg := datafs.NewRegistry()
 	d := &dataSourceFuncs{sr: datafs.NewSourceReader(reg)}
@@ -163,6 +201,17 @@ func TestDefineDatasource(t *testing.T) {
 	_, err = d.DefineDatasource("", "ftp://example.com/foo.yml")
 	require.Error(t, err)
 
+	// commands can't be run from templates
+	d = &dataSourceFuncs{sr: datafs.NewSourceReader(reg)}
+	_, err = d.DefineDatasource("cmd", "exec:kubectl?arg=version")
+	require.Error(t, err)
+
+	_, err = d.DefineDatasource("cmd", "merge:foo.json|exec:kubectl")
+	require.Error(t, err)
+
+	_, ok := reg.Lookup("cmd")
+	assert.False(t, ok)
+
 	reg = datafs.NewRegistry()
 	d = &dataSourceFuncs{sr: datafs.NewSourceReader(reg)}
 	_, err = d.DefineDatasource("data", "foo.json")
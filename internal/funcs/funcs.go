@@ -13,3 +13,20 @@ func checkExperimental(ctx context.Context) error {
 	}
 	return nil
 }
+
+// experimentalFuncs - functions that are only available in experimental mode
+var experimentalFuncs = map[string]bool{
+	"crypto.PBKDF2MCF":    true,
+	"crypto.Yescrypt":     true,
+	"crypto.YescryptMCF":  true,
+	"net.CIDRHost":        true,
+	"net.CIDRNetmask":     true,
+	"net.CIDRSubnets":     true,
+	"net.CIDRSubnetSizes": true,
+}
+
+// IsExperimental reports whether the named function (e.g. "net.CIDRHost") is
+// only available in experimental mode
+func IsExperimental(name string) bool {
+	return experimentalFuncs[name]
+}
@@ -0,0 +1,182 @@
+package funcs
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/deprecated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLintFunctionLists makes sure the lists of experimental and deprecated
+// functions used by the linter match the functions that actually call
+// checkExperimental or deprecated.WarnDeprecated
+func TestLintFunctionLists(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+
+	// namespace type names (e.g. "CryptoFuncs") to namespace names (e.g. "crypto")
+	namespaces := map[string]string{}
+
+	// type name -> method names
+	experimental := map[string][]string{}
+	deprecatedMethods := map[string][]string{}
+
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			if fn.Recv == nil {
+				if typ, ns := namespaceOf(fn); typ != "" {
+					namespaces[typ] = ns
+				}
+
+				continue
+			}
+
+			typ := receiverType(fn)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+
+				switch fun := call.Fun.(type) {
+				case *ast.Ident:
+					if fun.Name == "checkExperimental" {
+						experimental[typ] = append(experimental[typ], fn.Name.Name)
+					}
+				case *ast.SelectorExpr:
+					if x, ok := fun.X.(*ast.Ident); ok && x.Name == "deprecated" && fun.Sel.Name == "WarnDeprecated" {
+						deprecatedMethods[typ] = append(deprecatedMethods[typ], fn.Name.Name)
+					}
+				}
+
+				return true
+			})
+		}
+	}
+
+	qualify := func(methods map[string][]string) map[string]bool {
+		out := map[string]bool{}
+		for typ, names := range methods {
+			ns, ok := namespaces[typ]
+			require.True(t, ok, "no namespace found for %s", typ)
+
+			for _, name := range names {
+				out[ns+"."+name] = true
+			}
+		}
+		return out
+	}
+
+	assert.Equal(t, qualify(experimental), experimentalFuncs,
+		"experimentalFuncs must list exactly the functions that call checkExperimental")
+
+	listed := map[string]bool{}
+	for name := range deprecated.Functions {
+		listed[name] = true
+	}
+	assert.Equal(t, qualify(deprecatedMethods), listed,
+		"deprecated.Functions must list exactly the functions that call deprecated.WarnDeprecated")
+}
+
+// receiverType returns the name of the method's receiver type, without any
+// pointer
+func receiverType(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	if id, ok := typ.(*ast.Ident); ok {
+		return id.Name
+	}
+
+	return ""
+}
+
+// namespaceOf finds namespaces registered by Create*Funcs functions, like:
+//
+//	ns := &CryptoFuncs{ctx}
+//	f["crypto"] = func() any { return ns }
+func namespaceOf(fn *ast.FuncDecl) (typ, ns string) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if id, ok := n.Lhs[0].(*ast.Ident); ok && id.Name == "ns" {
+				if u, ok := n.Rhs[0].(*ast.UnaryExpr); ok {
+					if lit, ok := u.X.(*ast.CompositeLit); ok {
+						if t, ok := lit.Type.(*ast.Ident); ok {
+							typ = t.Name
+						}
+					}
+				}
+			}
+
+			if idx, ok := n.Lhs[0].(*ast.IndexExpr); ok && returnsNS(n.Rhs[0]) {
+				ns = stringLit(idx.Index)
+			}
+		case *ast.KeyValueExpr:
+			if returnsNS(n.Value) {
+				ns = stringLit(n.Key)
+			}
+		}
+
+		return true
+	})
+
+	if typ == "" || ns == "" {
+		return "", ""
+	}
+
+	return typ, ns
+}
+
+// returnsNS reports whether e is a function literal like func() any { return ns }
+func returnsNS(e ast.Expr) bool {
+	fl, ok := e.(*ast.FuncLit)
+	if !ok || len(fl.Body.List) != 1 {
+		return false
+	}
+
+	ret, ok := fl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return false
+	}
+
+	id, ok := ret.Results[0].(*ast.Ident)
+	return ok && id.Name == "ns"
+}
+
+func stringLit(e ast.Expr) string {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+
+	return s
+}
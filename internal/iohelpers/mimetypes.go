@@ -2,24 +2,42 @@ package iohelpers
 
 import (
 	"mime"
+	"strings"
 )
 
 const (
-	TextMimetype      = "text/plain"
-	CSVMimetype       = "text/csv"
-	JSONMimetype      = "application/json"
-	JSONArrayMimetype = "application/array+json"
-	TOMLMimetype      = "application/toml"
-	YAMLMimetype      = "application/yaml"
-	EnvMimetype       = "application/x-env"
-	CUEMimetype       = "application/cue"
+	TextMimetype       = "text/plain"
+	CSVMimetype        = "text/csv"
+	TSVMimetype        = "text/tab-separated-values"
+	JSONMimetype       = "application/json"
+	JSONArrayMimetype  = "application/array+json"
+	TOMLMimetype       = "application/toml"
+	YAMLMimetype       = "application/yaml"
+	EnvMimetype        = "application/x-env"
+	CUEMimetype        = "application/cue"
+	XMLMimetype        = "application/xml"
+	HCLMimetype        = "application/hcl"
+	INIMimetype        = "text/x-ini"
+	PropertiesMimetype = "text/x-java-properties"
+	NDJSONMimetype     = "application/x-ndjson"
+	JSON5Mimetype      = "application/json5"
+	JSONCMimetype      = "application/jsonc"
+	PlistMimetype      = "application/x-plist"
+	MarkdownMimetype   = "text/markdown"
 )
 
 // mimeTypeAliases defines a mapping for non-canonical mime types that are
 // sometimes seen in the wild
 var mimeTypeAliases = map[string]string{
-	"application/x-yaml": YAMLMimetype,
-	"application/text":   TextMimetype,
+	"application/x-yaml":      YAMLMimetype,
+	"application/text":        TextMimetype,
+	"text/xml":                XMLMimetype,
+	"application/x-hcl":       HCLMimetype,
+	"application/x-ini":       INIMimetype,
+	"application/jsonl":       NDJSONMimetype,
+	"application/x-jsonlines": NDJSONMimetype,
+	"application/x-bplist":    PlistMimetype,
+	"text/x-markdown":         MarkdownMimetype,
 }
 
 func MimeAlias(m string) string {
@@ -31,3 +49,29 @@ func MimeAlias(m string) string {
 	}
 	return m
 }
+
+// extensionMimeTypes defines the types for file extensions that aren't in the
+// standard MIME type database, or where the standard type isn't wanted
+var extensionMimeTypes = map[string]string{
+	// Markdown documents aren't usually data, so front matter is only parsed
+	// when the type is set explicitly
+	".md":         TextMimetype,
+	".markdown":   TextMimetype,
+	".hcl":        HCLMimetype,
+	".tfvars":     HCLMimetype,
+	".ini":        INIMimetype,
+	".properties": PropertiesMimetype,
+	".jsonl":      NDJSONMimetype,
+	".ndjson":     NDJSONMimetype,
+	".json5":      JSON5Mimetype,
+	".jsonc":      JSONCMimetype,
+	".tsv":        TSVMimetype,
+	".plist":      PlistMimetype,
+}
+
+// MimeTypeByExtension returns the MIME type for file extensions (including the
+// leading dot) that aren't in the standard MIME type database, or "" if the
+// extension isn't known
+func MimeTypeByExtension(ext string) string {
+	return extensionMimeTypes[strings.ToLower(ext)]
+}
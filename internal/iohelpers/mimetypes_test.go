@@ -14,9 +14,36 @@ func TestMimeAlias(t *testing.T) {
 		{CSVMimetype, CSVMimetype},
 		{YAMLMimetype, YAMLMimetype},
 		{"application/x-yaml", YAMLMimetype},
+		{"text/xml; charset=utf-8", XMLMimetype},
+		{"application/x-hcl", HCLMimetype},
+		{"application/x-ini", INIMimetype},
+		{"application/x-jsonlines", NDJSONMimetype},
+		{"application/x-bplist", PlistMimetype},
+		{"text/markdown; charset=utf-8", MarkdownMimetype},
+		{"text/x-markdown", MarkdownMimetype},
 	}
 
 	for _, d := range data {
 		assert.Equal(t, d.out, MimeAlias(d.in))
 	}
 }
+
+func TestMimeTypeByExtension(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, HCLMimetype, MimeTypeByExtension(".hcl"))
+	assert.Equal(t, HCLMimetype, MimeTypeByExtension(".TFVARS"))
+	assert.Equal(t, INIMimetype, MimeTypeByExtension(".ini"))
+	assert.Equal(t, PropertiesMimetype, MimeTypeByExtension(".properties"))
+	assert.Equal(t, NDJSONMimetype, MimeTypeByExtension(".jsonl"))
+	assert.Equal(t, NDJSONMimetype, MimeTypeByExtension(".ndjson"))
+	assert.Equal(t, JSON5Mimetype, MimeTypeByExtension(".json5"))
+	assert.Equal(t, JSONCMimetype, MimeTypeByExtension(".jsonc"))
+	assert.Equal(t, TSVMimetype, MimeTypeByExtension(".tsv"))
+	assert.Equal(t, PlistMimetype, MimeTypeByExtension(".plist"))
+	// Markdown must be requested explicitly, since documents aren't usually data
+	assert.Equal(t, TextMimetype, MimeTypeByExtension(".md"))
+	assert.Equal(t, TextMimetype, MimeTypeByExtension(".markdown"))
+	assert.Empty(t, MimeTypeByExtension(".json"))
+	assert.Empty(t, MimeTypeByExtension(""))
+}
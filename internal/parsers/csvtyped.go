@@ -0,0 +1,314 @@
+package parsers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSV column types, for CSVOptions.Types
+const (
+	CSVString = "string"
+	CSVInt    = "int"
+	CSVFloat  = "float"
+	CSVBool   = "bool"
+)
+
+// CSVOptions - options for CSVTyped
+type CSVOptions struct {
+	// Types - the types of columns, by name. Columns not listed here have
+	// their types inferred.
+	Types map[string]string
+
+	// Delimiter - the field delimiter, defaults to ','
+	Delimiter rune
+
+	// Quote - the character used to quote fields, defaults to '"'
+	Quote rune
+
+	// NoHeader - set when the first record is data rather than column names.
+	// Columns are then named A, B, C, etc.
+	NoHeader bool
+}
+
+// CSVTyped - Unmarshal CSV into an array of rows, indexed by the column name,
+// with values converted to the type of their column.
+//
+// Unless given in opts.Types, each column's type is inferred from its values -
+// a column is a bool, int, or float column when all of its non-empty values
+// can be parsed as that type, and otherwise a string column. Numbers with
+// leading zeros (like ZIP codes) are not considered to be numbers, and only
+// "true" and "false" (in any case) are considered to be booleans. Empty values
+// are nil, except in columns explicitly typed as strings.
+func CSVTyped(in string, opts CSVOptions) ([]map[string]any, error) {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.Quote == 0 {
+		opts.Quote = '"'
+	}
+	if opts.Delimiter == opts.Quote {
+		return nil, fmt.Errorf("unable to unmarshal CSV: delimiter and quote must be different")
+	}
+
+	records, err := readCSV(in, opts.Delimiter, opts.Quote)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal CSV: %w", err)
+	}
+
+	var hdr []string
+	switch {
+	case opts.NoHeader && len(records) > 0:
+		hdr = make([]string, len(records[0]))
+		for i := range hdr {
+			hdr[i] = autoIndex(i)
+		}
+	case len(records) > 0:
+		hdr = records[0]
+		records = records[1:]
+	}
+
+	// rows are indexed by column name, so names must be unique
+	for i, name := range hdr {
+		if j := slices.Index(hdr[:i], name); j != -1 {
+			return nil, fmt.Errorf("unable to unmarshal CSV: duplicate column name %q (columns %d and %d)", name, j+1, i+1)
+		}
+	}
+
+	types := make([]string, len(hdr))
+	hinted := make([]bool, len(hdr))
+	for name, typ := range opts.Types {
+		i := slices.Index(hdr, name)
+		if i == -1 {
+			return nil, fmt.Errorf("unable to unmarshal CSV: no column named %q", name)
+		}
+
+		if !slices.Contains([]string{CSVString, CSVInt, CSVFloat, CSVBool}, typ) {
+			return nil, fmt.Errorf("unable to unmarshal CSV: unsupported type %q for column %q", typ, name)
+		}
+
+		types[i] = typ
+		hinted[i] = true
+	}
+
+	for i := range types {
+		if types[i] == "" {
+			types[i] = inferCSVColumnType(records, i)
+		}
+	}
+
+	rows := make([]map[string]any, len(records))
+	for r, record := range records {
+		row := make(map[string]any, len(hdr))
+		for i, v := range record {
+			row[hdr[i]], err = csvValue(v, types[i], hinted[i])
+			if err != nil {
+				return nil, fmt.Errorf("unable to unmarshal CSV: row %d, column %q: %w", r+1, hdr[i], err)
+			}
+		}
+		rows[r] = row
+	}
+
+	return rows, nil
+}
+
+// inferCSVColumnType returns the narrowest type that all non-empty values in
+// column i can be parsed as
+func inferCSVColumnType(records [][]string, i int) string {
+	isBool, isInt, isFloat := true, true, true
+
+	for _, record := range records {
+		v := record[i]
+		if v == "" {
+			continue
+		}
+
+		_, err := csvParseBool(v)
+		isBool = isBool && err == nil
+		isInt = isInt && csvLooksNumeric(v) && !strings.ContainsAny(v, ".eE")
+		isFloat = isFloat && csvLooksNumeric(v)
+
+		if !isBool && !isFloat {
+			return CSVString
+		}
+	}
+
+	switch {
+	case isBool:
+		return CSVBool
+	case isInt:
+		// make sure values fit
+		for _, record := range records {
+			if _, err := strconv.Atoi(record[i]); record[i] != "" && err != nil {
+				return CSVFloat
+			}
+		}
+		return CSVInt
+	default:
+		return CSVFloat
+	}
+}
+
+// csvLooksNumeric returns true for decimal numbers without leading zeros.
+// strconv.ParseFloat also accepts things like "Inf" and "0x1p4", which are
+// much more likely to be strings in CSV.
+func csvLooksNumeric(v string) bool {
+	digits := strings.TrimLeft(v, "+-")
+	if len(v)-len(digits) > 1 || digits == "" {
+		return false
+	}
+
+	if digits[0] != '.' && (digits[0] < '0' || digits[0] > '9') {
+		return false
+	}
+
+	// "0", "0.5" and "0e1" are fine, but "01" isn't
+	if len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return false
+	}
+
+	if strings.ContainsAny(digits, "xX_") {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+func csvValue(v, typ string, hinted bool) (any, error) {
+	if v == "" && (typ != CSVString || !hinted) {
+		return nil, nil
+	}
+
+	switch typ {
+	case CSVInt:
+		return strconv.Atoi(v)
+	case CSVFloat:
+		return strconv.ParseFloat(v, 64)
+	case CSVBool:
+		return csvParseBool(v)
+	default:
+		return v, nil
+	}
+}
+
+// csvParseBool accepts only "true" and "false", in any case. strconv.ParseBool
+// also accepts things like "1" and "f", but not "tRUE".
+func csvParseBool(v string) (bool, error) {
+	switch {
+	case strings.EqualFold(v, "true"):
+		return true, nil
+	case strings.EqualFold(v, "false"):
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", v)
+	}
+}
+
+// readCSV reads all records, with the given delimiter and quote characters.
+// Quotes are only special at the start of a field, and are escaped inside
+// quoted fields by doubling them. Blank lines are skipped, and all records
+// must have the same number of fields.
+func readCSV(in string, delim, quote rune) ([][]string, error) {
+	records := [][]string{}
+	line := 1
+
+	for len(in) > 0 {
+		start := line
+		record, rest, err := readCSVRecord(in, delim, quote, &line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		in = rest
+
+		if record == nil {
+			continue
+		}
+
+		if len(records) > 0 && len(record) != len(records[0]) {
+			return nil, fmt.Errorf("line %d: wrong number of fields (expected %d, got %d)", start, len(records[0]), len(record))
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// readCSVRecord reads one record from in, returning the remaining input. A nil
+// record is returned for blank lines.
+func readCSVRecord(in string, delim, quote rune, line *int) ([]string, string, error) {
+	if rest, ok := cutLineEnding(in); ok {
+		*line++
+		return nil, rest, nil
+	}
+
+	record := []string{}
+	field := &strings.Builder{}
+
+	for {
+		r, size := utf8.DecodeRuneInString(in)
+
+		if r == quote && field.Len() == 0 {
+			// quoted field
+			in = in[size:]
+			closed := false
+			for len(in) > 0 {
+				r, size = utf8.DecodeRuneInString(in)
+				in = in[size:]
+
+				if r == quote {
+					if next, nsize := utf8.DecodeRuneInString(in); next == quote {
+						field.WriteRune(quote)
+						in = in[nsize:]
+						continue
+					}
+
+					closed = true
+					break
+				}
+
+				if r == '\n' {
+					*line++
+				}
+				field.WriteRune(r)
+			}
+
+			if !closed {
+				return nil, "", fmt.Errorf("quoted field not closed")
+			}
+
+			r, size = utf8.DecodeRuneInString(in)
+			if len(in) > 0 && r != delim && r != '\n' && r != '\r' {
+				return nil, "", fmt.Errorf("unexpected %q after quoted field", r)
+			}
+		}
+
+		if len(in) == 0 {
+			return append(record, field.String()), "", nil
+		}
+
+		if rest, ok := cutLineEnding(in); ok {
+			*line++
+			return append(record, field.String()), rest, nil
+		}
+
+		if r == delim {
+			record = append(record, field.String())
+			field.Reset()
+		} else {
+			field.WriteRune(r)
+		}
+		in = in[size:]
+	}
+}
+
+func cutLineEnding(in string) (string, bool) {
+	if rest, ok := strings.CutPrefix(in, "\r\n"); ok {
+		return rest, true
+	}
+
+	return strings.CutPrefix(in, "\n")
+}
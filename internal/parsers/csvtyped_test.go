@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVTyped(t *testing.T) {
+	in := `name,age,height,active,zip,score,notes
+Alice,30,1.62,true,01234,,"likes ""quotes"", commas"
+Bob,-4,2,FALSE,98765,1e3,
+Carol,,,,,7,"multi
+line"
+`
+	expected := []map[string]any{
+		{"name": "Alice", "age": 30, "height": 1.62, "active": true, "zip": "01234", "score": nil, "notes": `likes "quotes", commas`},
+		{"name": "Bob", "age": -4, "height": 2.0, "active": false, "zip": "98765", "score": 1000.0, "notes": nil},
+		{"name": "Carol", "age": nil, "height": nil, "active": nil, "zip": nil, "score": 7.0, "notes": "multi\nline"},
+	}
+
+	out, err := CSVTyped(in, CSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// type hints override inference
+	out, err = CSVTyped(in, CSVOptions{Types: map[string]string{
+		"zip":   CSVInt,
+		"age":   CSVString,
+		"notes": CSVString,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 1234, out[0]["zip"])
+	assert.Equal(t, "30", out[0]["age"])
+	assert.Equal(t, "", out[2]["age"])
+	assert.Equal(t, "", out[1]["notes"])
+
+	// TSV with a custom quote character and no header, CRLF line endings
+	in = "'a\tb'\t1\tInf\r\n\r\nc\t2\t0x10\r\n"
+	out, err = CSVTyped(in, CSVOptions{Delimiter: '\t', Quote: '\'', NoHeader: true})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"A": "a\tb", "B": 1, "C": "Inf"},
+		{"A": "c", "B": 2, "C": "0x10"},
+	}, out)
+
+	// ints too big for int are floats
+	out, err = CSVTyped("n\n99999999999999999999\n1\n", CSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"n": 99999999999999999999.0}, {"n": 1.0}}, out)
+
+	out, err = CSVTyped("", CSVOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	out, err = CSVTyped("a,b\n", CSVOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = CSVTyped("a,b\n1\n", CSVOptions{})
+	require.ErrorContains(t, err, "line 2")
+
+	_, err = CSVTyped("a\n\"unclosed\n", CSVOptions{})
+	require.Error(t, err)
+
+	_, err = CSVTyped("a\n\"x\"y\n", CSVOptions{})
+	require.Error(t, err)
+
+	_, err = CSVTyped("a\n1\n", CSVOptions{Types: map[string]string{"b": CSVInt}})
+	require.Error(t, err)
+
+	_, err = CSVTyped("a\n1\n", CSVOptions{Types: map[string]string{"a": "date"}})
+	require.Error(t, err)
+
+	// booleans are case-insensitive
+	out, err = CSVTyped("a\ntRUE\nfAlse\n", CSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"a": true}, {"a": false}}, out)
+
+	out, err = CSVTyped("a\ntRUE\nfAlse\n", CSVOptions{Types: map[string]string{"a": CSVBool}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"a": true}, {"a": false}}, out)
+
+	_, err = CSVTyped("a\n1\n", CSVOptions{Types: map[string]string{"a": CSVBool}})
+	require.ErrorContains(t, err, `row 1, column "a"`)
+
+	_, err = CSVTyped("a\nfoo\n", CSVOptions{Types: map[string]string{"a": CSVInt}})
+	require.ErrorContains(t, err, `row 1, column "a"`)
+
+	_, err = CSVTyped("a\n1\n", CSVOptions{Delimiter: '\'', Quote: '\''})
+	require.Error(t, err)
+
+	_, err = CSVTyped("a,b,a\n1,2,3\n", CSVOptions{})
+	require.ErrorContains(t, err, `duplicate column name "a" (columns 1 and 3)`)
+}
+
+func TestCSVLooksNumeric(t *testing.T) {
+	for _, v := range []string{"0", "1", "-1", "+1", "0.5", ".5", "5.", "1e3", "-1.5E-3", "0e1", "10"} {
+		assert.True(t, csvLooksNumeric(v), v)
+	}
+
+	for _, v := range []string{"", "-", "01", "-007", "00.5", "Inf", "NaN", "0x10", "1_000", "--1", "1,000", " 1", "1a"} {
+		assert.False(t, csvLooksNumeric(v), v)
+	}
+}
@@ -0,0 +1,99 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FrontMatter - Split a document (usually Markdown) into its front matter and
+// body, returning an object with "meta" and "body" keys. The front matter is
+// parsed as YAML when delimited by "---" lines (the closing line can also be
+// "..."), TOML when delimited by "+++" lines, or JSON when the document starts
+// with a JSON object. Documents without front matter have an empty "meta".
+func FrontMatter(in string) (map[string]any, error) {
+	in = strings.TrimPrefix(in, "\ufeff")
+
+	var (
+		meta any
+		body = in
+		err  error
+	)
+
+	first, rest := cutLine(in)
+	switch strings.TrimRight(first, " \t") {
+	case "---":
+		var fm string
+		fm, body, err = cutFrontMatter(rest, "---", "...")
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err = YAML(fm)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse YAML front matter: %w", err)
+		}
+	case "+++":
+		var fm string
+		fm, body, err = cutFrontMatter(rest, "+++")
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err = TOML(fm)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse TOML front matter: %w", err)
+		}
+	default:
+		if !strings.HasPrefix(in, "{") {
+			meta = map[string]any{}
+			break
+		}
+
+		// the front matter ends where the first JSON value does
+		d := json.NewDecoder(strings.NewReader(in))
+		var raw json.RawMessage
+		if err = d.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("unable to parse JSON front matter: %w", err)
+		}
+
+		end := int(d.InputOffset())
+		meta, err = JSON(in[:end])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse JSON front matter: %w", err)
+		}
+
+		// the rest of the closing line isn't part of the body
+		_, body = cutLine(in[end:])
+	}
+
+	return map[string]any{"meta": meta, "body": body}, nil
+}
+
+// cutFrontMatter splits in at the first line matching one of the closing
+// delimiters, returning the front matter before it and the body after it
+func cutFrontMatter(in string, closing ...string) (fm, body string, err error) {
+	rest := in
+	for rest != "" {
+		line, next := cutLine(rest)
+		for _, c := range closing {
+			if strings.TrimRight(line, " \t") == c {
+				return in[:len(in)-len(rest)], next, nil
+			}
+		}
+		rest = next
+	}
+
+	return "", "", fmt.Errorf("unable to parse front matter: closing %q not found", closing[0])
+}
+
+// cutLine returns the first line of in (without the line ending), and the
+// rest of in after it
+func cutLine(in string) (line, rest string) {
+	line, rest, found := strings.Cut(in, "\n")
+	if !found {
+		return in, ""
+	}
+
+	return strings.TrimSuffix(line, "\r"), rest
+}
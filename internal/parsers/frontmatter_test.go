@@ -0,0 +1,75 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontMatter(t *testing.T) {
+	testdata := []struct {
+		in   string
+		meta map[string]any
+		body string
+	}{
+		{
+			in:   "---\ntitle: Hello\ntags: [a, b]\n---\n# Hello\n\nworld\n",
+			meta: map[string]any{"title": "Hello", "tags": []any{"a", "b"}},
+			body: "# Hello\n\nworld\n",
+		},
+		{
+			// "..." can close YAML front matter, and CRLF line endings work
+			in:   "--- \r\ntitle: Hello\r\n...\r\nbody\r\n",
+			meta: map[string]any{"title": "Hello"},
+			body: "body\r\n",
+		},
+		{
+			in:   "+++\ntitle = \"Hello\"\ndraft = true\n+++\nbody\n",
+			meta: map[string]any{"title": "Hello", "draft": true},
+			body: "body\n",
+		},
+		{
+			in:   "{\n  \"title\": \"Hello\",\n  \"weight\": 2\n}\nbody\n",
+			meta: map[string]any{"title": "Hello", "weight": 2},
+			body: "body\n",
+		},
+		{
+			in:   "\ufeff---\ntitle: Hello\n---\n",
+			meta: map[string]any{"title": "Hello"},
+			body: "",
+		},
+		{
+			in:   "---\n---\nbody",
+			meta: map[string]any{},
+			body: "body",
+		},
+		{
+			// no front matter
+			in:   "# Hello\n\n---\n\nworld\n",
+			meta: map[string]any{},
+			body: "# Hello\n\n---\n\nworld\n",
+		},
+		{
+			in:   "",
+			meta: map[string]any{},
+			body: "",
+		},
+	}
+
+	for _, d := range testdata {
+		out, err := FrontMatter(d.in)
+		require.NoError(t, err, d.in)
+		assert.Equal(t, map[string]any{"meta": d.meta, "body": d.body}, out, d.in)
+	}
+
+	for _, in := range []string{
+		"---\ntitle: Hello\nbody\n",
+		"---\ntitle: [\n---\nbody\n",
+		"+++\ntitle = \n+++\nbody\n",
+		"{\"title\": \"Hello\"\nbody\n",
+	} {
+		_, err := FrontMatter(in)
+		require.Error(t, err, in)
+	}
+}
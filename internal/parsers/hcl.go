@@ -0,0 +1,248 @@
+package parsers
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v5/conv"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCL - Unmarshal an HCL document (e.g. a Terraform .tfvars file or a Nomad job
+// file) into an object.
+//
+// Attributes are mapped to keys, and blocks are nested objects keyed by the
+// block type and then each label - so `job "web" { ... }` becomes
+// `{"job": {"web": {...}}}`. Repeated blocks with the same type and labels are
+// arrays. Expressions that can't be evaluated without a context (i.e. that
+// reference variables or call functions) are kept as their source text.
+func HCL(in string) (map[string]any, error) {
+	src := []byte(in)
+
+	f, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("unable to unmarshal HCL: %w", diags)
+	}
+
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unable to unmarshal HCL: unexpected body type %T", f.Body)
+	}
+
+	return hclBody(body, src)
+}
+
+func hclBody(body *hclsyntax.Body, src []byte) (map[string]any, error) {
+	obj := map[string]any{}
+
+	for name, attr := range body.Attributes {
+		obj[name] = hclExpr(attr.Expr, src)
+	}
+
+	for _, block := range body.Blocks {
+		v, err := hclBody(block.Body, src)
+		if err != nil {
+			return nil, err
+		}
+
+		// nest the block in an object for each label
+		parent := obj
+		keys := append([]string{block.Type}, block.Labels...)
+		for _, k := range keys[:len(keys)-1] {
+			child, ok := parent[k].(map[string]any)
+			if !ok {
+				if _, exists := parent[k]; exists {
+					return nil, fmt.Errorf("unable to unmarshal HCL: block %q conflicts with attribute %q",
+						strings.Join(keys, "."), k)
+				}
+
+				child = map[string]any{}
+				parent[k] = child
+			}
+			parent = child
+		}
+
+		k := keys[len(keys)-1]
+		switch existing := parent[k].(type) {
+		case nil:
+			parent[k] = v
+		case []any:
+			parent[k] = append(existing, v)
+		default:
+			parent[k] = []any{existing, v}
+		}
+	}
+
+	return obj, nil
+}
+
+// hclExpr evaluates the expression, or returns its source text if it can't be
+// evaluated without a context
+func hclExpr(expr hclsyntax.Expression, src []byte) any {
+	v, diags := expr.Value(nil)
+	if !diags.HasErrors() {
+		return ctyToAny(v)
+	}
+
+	s := string(expr.Range().SliceBytes(src))
+
+	// quoted templates are strings, so the quotes aren't needed
+	switch expr.(type) {
+	case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr:
+		if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+			s = s[1 : len(s)-1]
+		}
+	}
+
+	return s
+}
+
+func ctyToAny(v cty.Value) any {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Bool:
+		return v.True()
+	case t == cty.Number:
+		bf := v.AsBigFloat()
+		if i, acc := bf.Int64(); bf.IsInt() && acc == big.Exact {
+			return i
+		}
+		f, _ := bf.Float64()
+		return f
+	case t.IsObjectType() || t.IsMapType():
+		obj := map[string]any{}
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			obj[k.AsString()] = ctyToAny(ev)
+		}
+		return obj
+	case t.IsTupleType() || t.IsListType() || t.IsSetType():
+		arr := []any{}
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			arr = append(arr, ctyToAny(ev))
+		}
+		return arr
+	default:
+		return nil
+	}
+}
+
+// ToHCL - Stringify an object as an HCL document. Each key is written as an
+// attribute (nested objects are object expressions, not blocks), which is
+// suitable for Terraform .tfvars files.
+func ToHCL(in any) (string, error) {
+	rv := reflect.ValueOf(in)
+	if rv.Kind() != reflect.Map {
+		return "", fmt.Errorf("unable to marshal HCL: expected an object, got %T", in)
+	}
+
+	attrs := map[string]cty.Value{}
+	for iter := rv.MapRange(); iter.Next(); {
+		k := conv.ToString(iter.Key().Interface())
+		if !hclsyntax.ValidIdentifier(k) {
+			return "", fmt.Errorf("unable to marshal HCL: %q is not a valid attribute name", k)
+		}
+
+		v, err := anyToCty(iter.Value().Interface())
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal HCL: %w", err)
+		}
+
+		attrs[k] = v
+	}
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	// sorted, so that output is stable
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		body.SetAttributeValue(k, attrs[k])
+	}
+
+	return string(hclwrite.Format(f.Bytes())), nil
+}
+
+func anyToCty(in any) (cty.Value, error) {
+	rv := reflect.ValueOf(in)
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Invalid, reflect.Interface, reflect.Pointer:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case reflect.String:
+		return cty.StringVal(rv.String()), nil
+	case reflect.Bool:
+		return cty.BoolVal(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cty.NumberIntVal(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cty.NumberUIntVal(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cty.NumberFloatVal(rv.Float()), nil
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+
+		obj := map[string]cty.Value{}
+		for iter := rv.MapRange(); iter.Next(); {
+			v, err := anyToCty(iter.Value().Interface())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			obj[conv.ToString(iter.Key().Interface())] = v
+		}
+
+		return cty.ObjectVal(obj), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is text, not an array
+			return cty.StringVal(string(rv.Bytes())), nil
+		}
+
+		if rv.Len() == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+
+		arr := make([]cty.Value, rv.Len())
+		for i := range rv.Len() {
+			v, err := anyToCty(rv.Index(i).Interface())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			arr[i] = v
+		}
+
+		return cty.TupleVal(arr), nil
+	default:
+		if s, ok := in.(fmt.Stringer); ok {
+			return cty.StringVal(s.String()), nil
+		}
+
+		return cty.NilVal, fmt.Errorf("unsupported type %T", in)
+	}
+}
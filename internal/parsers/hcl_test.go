@@ -0,0 +1,165 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHCL(t *testing.T) {
+	in := `# terraform.tfvars
+region        = "us-east-1"
+instance_count = 3
+ratio         = 0.5
+enabled       = true
+zones         = ["a", "b"]
+tags = {
+  team = "platform"
+  "cost-center" = 42
+}
+nothing = null
+`
+	expected := map[string]any{
+		"region":         "us-east-1",
+		"instance_count": int64(3),
+		"ratio":          0.5,
+		"enabled":        true,
+		"zones":          []any{"a", "b"},
+		"tags":           map[string]any{"team": "platform", "cost-center": int64(42)},
+		"nothing":        nil,
+	}
+
+	out, err := HCL(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// a Nomad job file
+	in = `job "web" {
+  datacenters = ["dc1"]
+
+  group "app" {
+    count = 2
+
+    task "server" {
+      driver = "docker"
+      env {
+        HOST = "${attr.unique.network.ip-address}"
+      }
+    }
+
+    task "sidecar" {
+      driver = "exec"
+    }
+  }
+
+  constraint {
+    attribute = "${attr.kernel.name}"
+    value     = "linux"
+  }
+
+  constraint {
+    distinct_hosts = true
+  }
+}
+`
+	expected = map[string]any{
+		"job": map[string]any{
+			"web": map[string]any{
+				"datacenters": []any{"dc1"},
+				"group": map[string]any{
+					"app": map[string]any{
+						"count": int64(2),
+						"task": map[string]any{
+							"server": map[string]any{
+								"driver": "docker",
+								"env": map[string]any{
+									"HOST": "${attr.unique.network.ip-address}",
+								},
+							},
+							"sidecar": map[string]any{"driver": "exec"},
+						},
+					},
+				},
+				"constraint": []any{
+					map[string]any{"attribute": "${attr.kernel.name}", "value": "linux"},
+					map[string]any{"distinct_hosts": true},
+				},
+			},
+		},
+	}
+
+	out, err = HCL(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// expressions which can't be evaluated are kept as source
+	out, err = HCL(`id = var.id
+name = upper("foo")
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "var.id", "name": `upper("foo")`}, out)
+
+	_, err = HCL(`foo = `)
+	require.Error(t, err)
+
+	_, err = HCL("foo = 1\nfoo { }\nfoo \"bar\" { }")
+	require.Error(t, err)
+}
+
+func TestToHCL(t *testing.T) {
+	in := map[string]any{
+		"region":         "us-east-1",
+		"instance_count": 3,
+		"ratio":          0.5,
+		"enabled":        true,
+		"zones":          []any{"a", "b"},
+		"tags":           map[string]any{"team": "platform", "cost-center": int64(42)},
+		"nothing":        nil,
+		"empty":          []string{},
+	}
+
+	expected := `empty          = []
+enabled        = true
+instance_count = 3
+nothing        = null
+ratio          = 0.5
+region         = "us-east-1"
+tags = {
+  cost-center = 42
+  team        = "platform"
+}
+zones = ["a", "b"]
+`
+
+	out, err := ToHCL(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	parsed, err := HCL(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"region":         "us-east-1",
+		"instance_count": int64(3),
+		"ratio":          0.5,
+		"enabled":        true,
+		"zones":          []any{"a", "b"},
+		"tags":           map[string]any{"team": "platform", "cost-center": int64(42)},
+		"nothing":        nil,
+		"empty":          []any{},
+	}, parsed)
+
+	out, err = ToHCL(map[string]string{"name": "${not.interpolated}"})
+	require.NoError(t, err)
+	assert.Equal(t, "name = \"$${not.interpolated}\"\n", out)
+
+	_, err = ToHCL([]any{"a"})
+	require.Error(t, err)
+
+	_, err = ToHCL(map[string]any{"not valid": 1})
+	require.Error(t, err)
+
+	_, err = ToHCL(map[string]any{"ch": make(chan int)})
+	require.Error(t, err)
+}
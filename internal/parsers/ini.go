@@ -0,0 +1,200 @@
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v5/conv"
+)
+
+// INI - Unmarshal an INI file (e.g. php.ini or a systemd unit) into an object.
+//
+// Keys before the first section are top-level keys, and each section is a
+// nested object. Values are strings, with surrounding whitespace and matching
+// quotes removed. Repeated keys in a section are arrays, keys without a value
+// are empty strings, and lines ending with a backslash are continued on the
+// next line. Lines starting with ';' or '#' are comments.
+func INI(in string) (map[string]any, error) {
+	obj := map[string]any{}
+	section := obj
+
+	s := bufio.NewScanner(strings.NewReader(in))
+	lineNum := 0
+
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+
+		for strings.HasSuffix(line, `\`) && s.Scan() {
+			lineNum++
+			line = strings.TrimSpace(strings.TrimSuffix(line, `\`)) + " " + strings.TrimSpace(s.Text())
+		}
+
+		switch {
+		case line == "", line[0] == ';', line[0] == '#':
+			continue
+		case line[0] == '[':
+			name, ok := strings.CutSuffix(line[1:], "]")
+			if !ok {
+				return nil, fmt.Errorf("unable to unmarshal INI: unterminated section header on line %d", lineNum)
+			}
+			name = strings.TrimSpace(name)
+
+			// repeated sections are merged
+			section, ok = obj[name].(map[string]any)
+			if !ok {
+				if _, exists := obj[name]; exists {
+					return nil, fmt.Errorf("unable to unmarshal INI: section [%s] on line %d conflicts with key %q", name, lineNum, name)
+				}
+
+				section = map[string]any{}
+				obj[name] = section
+			}
+		default:
+			k, v, _ := strings.Cut(line, "=")
+			k = strings.TrimSpace(k)
+			v = iniUnquote(strings.TrimSpace(v))
+
+			switch existing := section[k].(type) {
+			case nil:
+				section[k] = v
+			case []any:
+				section[k] = append(existing, v)
+			case string:
+				section[k] = []any{existing, v}
+			default:
+				return nil, fmt.Errorf("unable to unmarshal INI: key %q on line %d conflicts with section [%s]", k, lineNum, k)
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal INI: %w", err)
+	}
+
+	return obj, nil
+}
+
+func iniUnquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+
+	return v
+}
+
+// ToINI - Stringify an object as an INI file. Keys with object values are
+// written as sections, after the top-level keys, and array values are written
+// as repeated keys. Keys are sorted.
+func ToINI(in any) (string, error) {
+	obj, err := iniObject(in)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal INI: %w", err)
+	}
+
+	top := &strings.Builder{}
+	sections := &strings.Builder{}
+
+	for _, k := range sortedMapKeys(obj) {
+		v := reflect.ValueOf(obj[k])
+		if v.Kind() != reflect.Map {
+			err = iniWriteKey(top, k, obj[k])
+			if err != nil {
+				return "", fmt.Errorf("unable to marshal INI: %w", err)
+			}
+
+			continue
+		}
+
+		section, err := iniObject(obj[k])
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal INI: section [%s]: %w", k, err)
+		}
+
+		fmt.Fprintf(sections, "\n[%s]\n", k)
+
+		for _, sk := range sortedMapKeys(section) {
+			err = iniWriteKey(sections, sk, section[sk])
+			if err != nil {
+				return "", fmt.Errorf("unable to marshal INI: section [%s]: %w", k, err)
+			}
+		}
+	}
+
+	if top.Len() == 0 {
+		return strings.TrimPrefix(sections.String(), "\n"), nil
+	}
+
+	return top.String() + sections.String(), nil
+}
+
+// iniObject converts a map with any key and value types to a map[string]any
+func iniObject(in any) (map[string]any, error) {
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected an object, got %T", in)
+	}
+
+	obj := make(map[string]any, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		obj[conv.ToString(iter.Key().Interface())] = iter.Value().Interface()
+	}
+
+	return obj, nil
+}
+
+func iniWriteKey(w *strings.Builder, k string, v any) error {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Map:
+		return fmt.Errorf("key %q: nested sections are not supported", k)
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := range rv.Len() {
+				err := iniWriteKey(w, k, rv.Index(i).Interface())
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	s := ""
+	if v != nil {
+		s = conv.ToString(v)
+	}
+
+	if strings.ContainsAny(s, "\r\n") {
+		return fmt.Errorf("key %q: values can't contain newlines", k)
+	}
+
+	// quote values that would otherwise be changed when parsed
+	if s != strings.TrimSpace(s) || iniUnquote(s) != s || strings.HasSuffix(s, `\`) {
+		s = `"` + s + `"`
+	}
+
+	if s == "" {
+		fmt.Fprintf(w, "%s =\n", k)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s = %s\n", k, s)
+
+	return nil
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
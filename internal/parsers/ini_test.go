@@ -0,0 +1,150 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestINI(t *testing.T) {
+	in := `; php.ini
+engine = On
+short_open_tag = Off
+
+[Date]
+date.timezone = "America/Toronto"
+
+[mail function]
+SMTP = localhost
+sendmail_path = '/usr/sbin/sendmail -t -i'
+`
+	expected := map[string]any{
+		"engine":         "On",
+		"short_open_tag": "Off",
+		"Date": map[string]any{
+			"date.timezone": "America/Toronto",
+		},
+		"mail function": map[string]any{
+			"SMTP":          "localhost",
+			"sendmail_path": "/usr/sbin/sendmail -t -i",
+		},
+	}
+
+	out, err := INI(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// a systemd unit, with repeated keys, continued lines and an empty value
+	in = `[Unit]
+Description=My Service
+After=network.target
+
+[Service]
+# clear the inherited value, then add more
+ExecStartPre=
+ExecStartPre=/bin/mkdir -p /run/app
+ExecStartPre=/bin/chown app /run/app
+ExecStart=/usr/bin/app \
+  --port 8080 \
+  --verbose
+[Install]
+WantedBy=multi-user.target
+
+[Unit]
+Wants=network-online.target
+`
+	expected = map[string]any{
+		"Unit": map[string]any{
+			"Description": "My Service",
+			"After":       "network.target",
+			"Wants":       "network-online.target",
+		},
+		"Service": map[string]any{
+			"ExecStartPre": []any{"", "/bin/mkdir -p /run/app", "/bin/chown app /run/app"},
+			"ExecStart":    "/usr/bin/app --port 8080 --verbose",
+		},
+		"Install": map[string]any{
+			"WantedBy": "multi-user.target",
+		},
+	}
+
+	out, err = INI(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// keys without values (e.g. in my.cnf)
+	out, err = INI("[mysqld]\nskip-name-resolve\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"mysqld": map[string]any{"skip-name-resolve": ""}}, out)
+
+	out, err = INI("")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, out)
+
+	_, err = INI("[foo\nbar = baz\n")
+	require.Error(t, err)
+
+	_, err = INI("foo = bar\n[foo]\n")
+	require.Error(t, err)
+
+	_, err = INI("[foo]\n[bar]\nfoo = 1\n")
+	require.NoError(t, err)
+}
+
+func TestToINI(t *testing.T) {
+	in := map[string]any{
+		"engine": "On",
+		"port":   8080,
+		"Date":   map[string]any{"date.timezone": "America/Toronto"},
+		"Service": map[string]any{
+			"ExecStartPre": []any{"", "/bin/true"},
+			"Padded":       " x ",
+			"Quoted":       `"q"`,
+		},
+	}
+
+	expected := `engine = On
+port = 8080
+
+[Date]
+date.timezone = America/Toronto
+
+[Service]
+ExecStartPre =
+ExecStartPre = /bin/true
+Padded = " x "
+Quoted = ""q""
+`
+
+	out, err := ToINI(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	parsed, err := INI(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"engine": "On",
+		"port":   "8080",
+		"Date":   map[string]any{"date.timezone": "America/Toronto"},
+		"Service": map[string]any{
+			"ExecStartPre": []any{"", "/bin/true"},
+			"Padded":       " x ",
+			"Quoted":       `"q"`,
+		},
+	}, parsed)
+
+	out, err = ToINI(map[string]map[string]string{"a": {"b": "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, "[a]\nb = c\n", out)
+
+	_, err = ToINI([]any{"a"})
+	require.Error(t, err)
+
+	_, err = ToINI(map[string]any{"a": map[string]any{"b": map[string]any{"c": "d"}}})
+	require.Error(t, err)
+
+	_, err = ToINI(map[string]any{"a": "multi\nline"})
+	require.Error(t, err)
+}
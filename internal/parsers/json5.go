@@ -0,0 +1,461 @@
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hairyhenderson/yaml"
+)
+
+// JSON5 - Unmarshal a JSON5 document, or a JSONC document (JSON with comments
+// and trailing commas, as used by tsconfig.json and VS Code settings), into an
+// object or array.
+//
+// JSON5 allows comments, trailing commas, unquoted keys, single-quoted and
+// multi-line strings, hexadecimal numbers, leading and trailing decimal points,
+// explicit plus signs, and Infinity and NaN. The document is translated to
+// JSON and decoded the same way as JSON, so that values have the same types.
+func JSON5(in string) (any, error) {
+	s, err := json5ToJSON(in)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal JSON5: %w", err)
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal([]byte(s), &doc)
+	if err == nil {
+		// as with JSON, the last of any duplicated keys wins
+		dropDuplicateKeys(&doc)
+
+		var out any
+		err = doc.Decode(&out)
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	// the translated document is decoded as YAML, which is an implementation
+	// detail that shouldn't leak into errors
+	return nil, fmt.Errorf("unable to unmarshal JSON5: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+// dropDuplicateKeys removes all but the last occurrence of each key in the
+// node's mappings
+func dropDuplicateKeys(n *yaml.Node) {
+	for _, c := range n.Content {
+		dropDuplicateKeys(c)
+	}
+
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+
+	last := map[string]int{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		last[n.Content[i].Value] = i
+	}
+
+	content := make([]*yaml.Node, 0, len(last)*2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if last[n.Content[i].Value] == i {
+			content = append(content, n.Content[i], n.Content[i+1])
+		}
+	}
+	n.Content = content
+}
+
+// json5Translator translates JSON5 to JSON. Whitespace (other than newlines
+// outside of strings, which are kept so that line numbers in later errors are
+// mostly accurate) and comments are removed. Infinity and NaN have no JSON
+// representation, so they are written as YAML's .inf and .nan, which are
+// understood by the decoder.
+type json5Translator struct {
+	in   string
+	out  []byte
+	pos  int
+	line int
+
+	// stack of open containers - '{' or '['
+	stack []byte
+
+	// what's expected next
+	state json5State
+}
+
+// json5State is what the translator expects to read next
+type json5State int
+
+const (
+	// a value, at the start of the document or after a ':'
+	json5Value json5State = iota
+	// an array element, or the end of the array
+	json5Element
+	// an object key, or the end of the object
+	json5Key
+	// the ':' after an object key
+	json5Colon
+	// a ',' or the end of the container, or the end of the document
+	json5Next
+)
+
+func json5ToJSON(in string) (string, error) {
+	t := &json5Translator{in: strings.TrimPrefix(in, "\ufeff"), line: 1}
+
+	err := t.translate()
+	if err != nil {
+		return "", fmt.Errorf("line %d: %w", t.line, err)
+	}
+
+	return string(t.out), nil
+}
+
+func (t *json5Translator) translate() error {
+	for t.pos < len(t.in) {
+		c := t.in[t.pos]
+
+		switch {
+		case c == '\n':
+			t.out = append(t.out, '\n')
+			t.line++
+			t.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f':
+			t.pos++
+		case c == '/':
+			err := t.comment()
+			if err != nil {
+				return err
+			}
+		case c == '{' || c == '[':
+			if !t.expectValue() {
+				return fmt.Errorf("unexpected %q", c)
+			}
+
+			t.stack = append(t.stack, c)
+			t.state = json5Element
+			if c == '{' {
+				t.state = json5Key
+			}
+			t.out = append(t.out, c)
+			t.pos++
+		case c == '}' || c == ']':
+			open := byte('{')
+			if c == ']' {
+				open = '['
+			}
+			if len(t.stack) == 0 || t.stack[len(t.stack)-1] != open ||
+				t.state == json5Value || t.state == json5Colon {
+				return fmt.Errorf("unexpected %q", c)
+			}
+			t.stack = t.stack[:len(t.stack)-1]
+
+			t.dropTrailingComma()
+			t.out = append(t.out, c)
+			t.pos++
+			t.state = json5Next
+		case c == ',':
+			if t.state != json5Next || len(t.stack) == 0 {
+				return fmt.Errorf("unexpected %q", c)
+			}
+
+			t.state = json5Element
+			if t.stack[len(t.stack)-1] == '{' {
+				t.state = json5Key
+			}
+			t.out = append(t.out, c)
+			t.pos++
+		case c == ':':
+			if t.state != json5Colon {
+				return fmt.Errorf("unexpected %q", c)
+			}
+
+			t.state = json5Value
+			t.out = append(t.out, c)
+			t.pos++
+		case c == '"' || c == '\'':
+			key := t.state == json5Key
+			if !key && !t.expectValue() {
+				return fmt.Errorf("unexpected %q", c)
+			}
+
+			err := t.string(c)
+			if err != nil {
+				return err
+			}
+
+			t.state = json5Next
+			if key {
+				t.state = json5Colon
+			}
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			if !t.expectValue() {
+				return fmt.Errorf("unexpected %q", c)
+			}
+
+			err := t.number()
+			if err != nil {
+				return err
+			}
+
+			t.state = json5Next
+		default:
+			r, size := utf8.DecodeRuneInString(t.in[t.pos:])
+			if unicode.IsSpace(r) || r == '\ufeff' {
+				t.pos += size
+				continue
+			}
+
+			err := t.identifier()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(t.stack) > 0 {
+		return fmt.Errorf("unexpected end of input, %q not closed", t.stack[len(t.stack)-1])
+	}
+
+	if t.state != json5Next {
+		return fmt.Errorf("unexpected end of input")
+	}
+
+	return nil
+}
+
+// expectValue returns true if a value can be read next
+func (t *json5Translator) expectValue() bool {
+	return t.state == json5Value || t.state == json5Element
+}
+
+func (t *json5Translator) comment() error {
+	rest := t.in[t.pos:]
+
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		end := strings.IndexByte(rest, '\n')
+		if end == -1 {
+			end = len(rest)
+		}
+		t.pos += end
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end == -1 {
+			return fmt.Errorf("unterminated comment")
+		}
+
+		comment := rest[:end+4]
+		for range strings.Count(comment, "\n") {
+			t.out = append(t.out, '\n')
+			t.line++
+		}
+		t.pos += len(comment)
+	default:
+		return fmt.Errorf("unexpected '/'")
+	}
+
+	return nil
+}
+
+// dropTrailingComma removes a comma written before a closing bracket
+func (t *json5Translator) dropTrailingComma() {
+	for i := len(t.out) - 1; i >= 0; i-- {
+		switch t.out[i] {
+		case '\n':
+			continue
+		case ',':
+			t.out = append(t.out[:i], t.out[i+1:]...)
+		}
+
+		return
+	}
+}
+
+// string translates a single- or double-quoted JSON5 string to a JSON string
+func (t *json5Translator) string(quote byte) error {
+	t.out = append(t.out, '"')
+	t.pos++
+
+	for t.pos < len(t.in) {
+		c := t.in[t.pos]
+
+		switch c {
+		case quote:
+			t.out = append(t.out, '"')
+			t.pos++
+			return nil
+		case '"':
+			// only possible in single-quoted strings
+			t.out = append(t.out, '\\', '"')
+			t.pos++
+		case '\n', '\r':
+			return fmt.Errorf("unterminated string")
+		case '\\':
+			err := t.escape()
+			if err != nil {
+				return err
+			}
+		default:
+			t.out = append(t.out, c)
+			t.pos++
+		}
+	}
+
+	return fmt.Errorf("unterminated string")
+}
+
+func (t *json5Translator) escape() error {
+	t.pos++
+	if t.pos >= len(t.in) {
+		return fmt.Errorf("unterminated string")
+	}
+
+	c := t.in[t.pos]
+	t.pos++
+
+	switch c {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		t.out = append(t.out, '\\', c)
+	case '\'':
+		t.out = append(t.out, '\'')
+	case 'v':
+		t.out = append(t.out, `\u000b`...)
+	case '0':
+		if t.pos < len(t.in) && t.in[t.pos] >= '0' && t.in[t.pos] <= '9' {
+			return fmt.Errorf("octal escapes are not allowed")
+		}
+		t.out = append(t.out, `\u0000`...)
+	case 'x':
+		if t.pos+2 > len(t.in) {
+			return fmt.Errorf("malformed \\x escape")
+		}
+
+		_, err := strconv.ParseUint(t.in[t.pos:t.pos+2], 16, 8)
+		if err != nil {
+			return fmt.Errorf("malformed \\x escape %q", t.in[t.pos-2:t.pos+2])
+		}
+
+		t.out = append(t.out, `\u00`...)
+		t.out = append(t.out, t.in[t.pos:t.pos+2]...)
+		t.pos += 2
+	case 'u':
+		t.out = append(t.out, '\\', 'u')
+	case '\n':
+		// line continuation - the line break isn't part of the string
+		t.line++
+	case '\r':
+		if t.pos < len(t.in) && t.in[t.pos] == '\n' {
+			t.pos++
+		}
+		t.line++
+	default:
+		if c >= '1' && c <= '9' {
+			return fmt.Errorf("invalid escape '\\%c'", c)
+		}
+
+		// any other character is escaped to itself
+		r, size := utf8.DecodeRuneInString(t.in[t.pos-1:])
+		t.out = utf8.AppendRune(t.out, r)
+		t.pos += size - 1
+	}
+
+	return nil
+}
+
+func (t *json5Translator) number() error {
+	start := t.pos
+	for t.pos < len(t.in) && strings.IndexByte("+-.0123456789abcdefABCDEFxXInfinityNaN", t.in[t.pos]) >= 0 {
+		t.pos++
+	}
+	tok := t.in[start:t.pos]
+
+	sign := ""
+	num := tok
+	if num[0] == '+' || num[0] == '-' {
+		if num[0] == '-' {
+			sign = "-"
+		}
+		num = num[1:]
+	}
+
+	switch {
+	case num == "Infinity":
+		t.out = append(t.out, sign+".inf"...)
+	case num == "NaN":
+		t.out = append(t.out, ".nan"...)
+	case strings.HasPrefix(num, "0x") || strings.HasPrefix(num, "0X"):
+		n, err := strconv.ParseUint(num[2:], 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", tok)
+		}
+		t.out = append(t.out, sign+strconv.FormatUint(n, 10)...)
+	default:
+		// ParseFloat accepts more than JSON5 does (e.g. "Inf")
+		_, err := strconv.ParseFloat(num, 64)
+		if err != nil || num == "" || !strings.ContainsRune("0123456789.", rune(num[0])) {
+			return fmt.Errorf("invalid number %q", tok)
+		}
+
+		if len(num) > 1 && num[0] == '0' && num[1] >= '0' && num[1] <= '9' {
+			return fmt.Errorf("invalid number %q: leading zeros are not allowed", tok)
+		}
+
+		// JSON requires digits before and after the decimal point
+		if strings.HasPrefix(num, ".") {
+			num = "0" + num
+		}
+		num = strings.Replace(num, ".e", ".0e", 1)
+		num = strings.Replace(num, ".E", ".0E", 1)
+		if strings.HasSuffix(num, ".") {
+			num += "0"
+		}
+
+		t.out = append(t.out, sign+num...)
+	}
+
+	return nil
+}
+
+func (t *json5Translator) identifier() error {
+	start := t.pos
+	for t.pos < len(t.in) {
+		r, size := utf8.DecodeRuneInString(t.in[t.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		t.pos += size
+	}
+
+	id := t.in[start:t.pos]
+	if id == "" {
+		r, _ := utf8.DecodeRuneInString(t.in[t.pos:])
+		return fmt.Errorf("unexpected %q", r)
+	}
+
+	if t.state == json5Key {
+		t.state = json5Colon
+		t.out = strconv.AppendQuote(t.out, id)
+
+		return nil
+	}
+
+	if !t.expectValue() {
+		return fmt.Errorf("unexpected identifier %q", id)
+	}
+	t.state = json5Next
+
+	switch id {
+	case "true", "false", "null":
+		t.out = append(t.out, id...)
+	case "Infinity":
+		t.out = append(t.out, ".inf"...)
+	case "NaN":
+		t.out = append(t.out, ".nan"...)
+	default:
+		return fmt.Errorf("unexpected identifier %q", id)
+	}
+
+	return nil
+}
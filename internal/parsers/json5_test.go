@@ -0,0 +1,120 @@
+package parsers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON5(t *testing.T) {
+	// from https://json5.org
+	in := `// renovate.json5
+{
+  // comments
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  /* block
+     comment */
+  $special_key1: null,
+}
+`
+	expected := map[string]any{
+		"unquoted":            "and you can quote me on that",
+		"singleQuotes":        `I can use "double quotes" here`,
+		"lineBreaks":          `Look, Mom! No \n's!`,
+		"hexadecimal":         912559,
+		"leadingDecimalPoint": 0.8675309,
+		"andTrailing":         8675309.0,
+		"positiveSign":        1,
+		"trailingComma":       "in objects",
+		"andIn":               []any{"arrays"},
+		"backwardsCompatible": "with JSON",
+		"$special_key1":       nil,
+	}
+
+	out, err := JSON5(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// JSONC, as used by tsconfig.json
+	in = `{
+  "compilerOptions": {
+    "target": "es2022", // the output language level
+    "strict": true,
+    /* "noEmit": true, */
+    "paths": {"@/*": ["./src/*"],},
+  },
+}`
+	expected = map[string]any{
+		"compilerOptions": map[string]any{
+			"target": "es2022",
+			"strict": true,
+			"paths":  map[string]any{"@/*": []any{"./src/*"}},
+		},
+	}
+
+	out, err = JSON5(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	out, err = JSON5(`[1, -2.5e3, 'a\x41B\'', 'https://example.com', Infinity, -Infinity, -0x10, true, false,]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{1, -2500.0, "aAB'", "https://example.com", math.Inf(1), math.Inf(-1), -16, true, false}, out)
+
+	out, err = JSON5("NaN")
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(out.(float64)))
+
+	out, err = JSON5("\ufeff{a: 'b'}")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": "b"}, out)
+
+	for _, bad := range []string{
+		`{a: 1`,
+		`{a: 1]`,
+		`{a: 'unterminated}`,
+		`{a: /* unterminated }`,
+		`{a: foo}`,
+		`{a: -Inf}`,
+		`{a: 0xZZ}`,
+		`{a: '\01'}`,
+		`{a: 1} / 2`,
+		`{a: 007}`,
+		`[-01.5]`,
+		`[1 2]`,
+		`{a: 1 b: 2}`,
+		`{a 1}`,
+		`{a: }`,
+		`[,]`,
+		`[1,,]`,
+		`{1: 2}`,
+		`[1]]`,
+		`{} {}`,
+		``,
+	} {
+		_, err = JSON5(bad)
+		require.Error(t, err, bad)
+		assert.NotContains(t, err.Error(), "yaml", bad)
+	}
+
+	// as with JSON, the last duplicated key wins
+	out, err = JSON5(`{a: 1, b: {c: 1, c: 2}, 'a': 3}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 3, "b": map[string]any{"c": 2}}, out)
+
+	out, err = JSON5(`[0, -0.5, 0e1, 0x0F]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{0, -0.5, 0.0, 15}, out)
+
+	_, err = JSON5("{\n  a: 1,\n  b: nope,\n}")
+	require.ErrorContains(t, err, "line 3")
+}
@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hairyhenderson/yaml"
+)
+
+// NDJSON - Unmarshal a newline-delimited JSON (JSON Lines) stream into an
+// array with an element for each line. Blank lines are skipped.
+func NDJSON(in string) ([]any, error) {
+	out := []any{}
+
+	for i, line := range strings.Split(in, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// each line must be valid JSON on its own - the YAML parser (used for
+		// consistency with JSON) would accept more
+		if !json.Valid([]byte(line)) {
+			return nil, fmt.Errorf("unable to unmarshal NDJSON: line %d is not valid JSON", i+1)
+		}
+
+		var v any
+		err := yaml.Unmarshal([]byte(line), &v)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal NDJSON: line %d: %w", i+1, err)
+		}
+
+		out = append(out, v)
+	}
+
+	err := stringifyYAMLArrayMapKeys(out)
+	return out, err
+}
+
+// ToNDJSON - Stringify each element of an array as JSON, one per line
+func ToNDJSON(in any) (string, error) {
+	rv := reflect.ValueOf(in)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("unable to marshal NDJSON: expected an array, got %T", in)
+	}
+
+	sb := &strings.Builder{}
+	for i := range rv.Len() {
+		b, err := toJSONBytes(rv.Index(i).Interface())
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal NDJSON: element %d: %w", i, err)
+		}
+
+		sb.Write(b)
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
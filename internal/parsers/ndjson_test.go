@@ -0,0 +1,88 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSON(t *testing.T) {
+	in := `{"level":"info","msg":"started","port":8080}
+{"level":"error","msg":"failed","tags":["a","b"],"ratio":0.5}
+
+["an", "array"]
+"a string"
+42
+null
+`
+	expected := []any{
+		map[string]any{"level": "info", "msg": "started", "port": 8080},
+		map[string]any{"level": "error", "msg": "failed", "tags": []any{"a", "b"}, "ratio": 0.5},
+		[]any{"an", "array"},
+		"a string",
+		42,
+		nil,
+	}
+
+	out, err := NDJSON(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// CRLF line endings
+	out, err = NDJSON("{\"a\":1}\r\n{\"b\":2}\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"a": 1}, map[string]any{"b": 2}}, out)
+
+	out, err = NDJSON("")
+	require.NoError(t, err)
+	assert.Equal(t, []any{}, out)
+
+	_, err = NDJSON("{\"a\":1}\n{\"b\":\n2}\n")
+	require.ErrorContains(t, err, "line 2")
+
+	// YAML isn't JSON
+	_, err = NDJSON("a: 1\n")
+	require.Error(t, err)
+}
+
+func TestToNDJSON(t *testing.T) {
+	in := []any{
+		map[string]any{"msg": "started", "level": "info"},
+		[]string{"a", "b"},
+		"a string",
+		42,
+		nil,
+	}
+	expected := `{"level":"info","msg":"started"}
+["a","b"]
+"a string"
+42
+null
+`
+
+	out, err := ToNDJSON(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	parsed, err := NDJSON(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"msg": "started", "level": "info"},
+		[]any{"a", "b"},
+		"a string",
+		42,
+		nil,
+	}, parsed)
+
+	out, err = ToNDJSON([]any{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = ToNDJSON(map[string]any{"a": 1})
+	require.Error(t, err)
+
+	_, err = ToNDJSON([]any{&badObject{}})
+	require.Error(t, err)
+}
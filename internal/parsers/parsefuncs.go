@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -150,6 +151,31 @@ func YAMLArray(in string) ([]any, error) {
 	return obj, err
 }
 
+// YAMLDocuments - Unmarshal a multi-document YAML stream (such as a set of
+// Kubernetes manifests) into an array with an element for each document. Empty
+// documents are skipped.
+func YAMLDocuments(in string) ([]any, error) {
+	docs := []any{}
+	d := yaml.NewDecoder(strings.NewReader(in))
+	for {
+		var doc any
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal YAML document %d: %w", len(docs)+1, err)
+		}
+		if doc == nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+
+	err := stringifyYAMLArrayMapKeys(docs)
+	return docs, err
+}
+
 // stringifyYAMLArrayMapKeys recurses into the input array and changes all
 // non-string map keys to string map keys. Modifies the input array.
 func stringifyYAMLArrayMapKeys(in []any) error {
@@ -458,6 +484,26 @@ func ToYAML(in any) (string, error) {
 	return marshalObj(in, marshal)
 }
 
+// ToYAMLDocuments - Stringify each element of an array as a YAML document, in a
+// single "---"-separated stream
+func ToYAMLDocuments(in any) (string, error) {
+	rv := reflect.ValueOf(in)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("unable to marshal YAML documents: expected an array, got %T", in)
+	}
+
+	docs := make([]string, rv.Len())
+	for i := range rv.Len() {
+		doc, err := ToYAML(rv.Index(i).Interface())
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal YAML document %d: %w", i+1, err)
+		}
+		docs[i] = doc
+	}
+
+	return strings.Join(docs, "---\n"), nil
+}
+
 // ToTOML - Stringify a struct as TOML
 func ToTOML(in any) (string, error) {
 	buf := new(bytes.Buffer)
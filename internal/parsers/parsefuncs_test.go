@@ -260,6 +260,98 @@ key`: map[string]any{
 	assert.Equal(t, expected, out)
 }
 
+func TestYAMLDocuments(t *testing.T) {
+	in := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: chart/templates/empty.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      42: true
+---
+- a
+- b
+`
+	expected := []any{
+		map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]any{"name": "web"},
+		},
+		map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]any{
+				"replicas": 2,
+				"selector": map[string]any{
+					"matchLabels": map[string]any{"42": true},
+				},
+			},
+		},
+		[]any{"a", "b"},
+	}
+
+	out, err := YAMLDocuments(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	out, err = YAMLDocuments("")
+	require.NoError(t, err)
+	assert.Equal(t, []any{}, out)
+
+	out, err = YAMLDocuments("foo")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"foo"}, out)
+
+	_, err = YAMLDocuments("a: 1\n---\nb: [\n")
+	require.Error(t, err)
+}
+
+func TestToYAMLDocuments(t *testing.T) {
+	in := []any{
+		map[string]any{"kind": "Service", "metadata": map[string]any{"name": "web"}},
+		map[string]any{"kind": "Deployment"},
+		[]string{"a", "b"},
+	}
+	expected := `kind: Service
+metadata:
+  name: web
+---
+kind: Deployment
+---
+- a
+- b
+`
+
+	out, err := ToYAMLDocuments(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	docs, err := YAMLDocuments(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"kind": "Service", "metadata": map[string]any{"name": "web"}},
+		map[string]any{"kind": "Deployment"},
+		[]any{"a", "b"},
+	}, docs)
+
+	out, err = ToYAMLDocuments([]any{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = ToYAMLDocuments(map[string]any{"kind": "Service"})
+	require.Error(t, err)
+}
+
 func TestCSV(t *testing.T) {
 	expected := [][]string{
 		{"first", "second", "third"},
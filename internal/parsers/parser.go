@@ -2,7 +2,11 @@ package parsers
 
 import (
 	"fmt"
+	"mime"
+	"strconv"
+	"unicode/utf8"
 
+	"github.com/hairyhenderson/gomplate/v5/conv"
 	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
 )
 
@@ -16,14 +20,23 @@ func ParseData(mimeType, s string) (out any, err error) {
 		}
 	case iohelpers.JSONArrayMimetype:
 		out, err = JSONArray(s)
+	case iohelpers.NDJSONMimetype:
+		out, err = NDJSON(s)
+	case iohelpers.JSON5Mimetype, iohelpers.JSONCMimetype:
+		out, err = JSON5(s)
 	case iohelpers.YAMLMimetype:
+		if isMultidoc(mimeType) {
+			out, err = YAMLDocuments(s)
+			break
+		}
+
 		out, err = YAML(s)
 		if err != nil {
 			// maybe it's a YAML array
 			out, err = YAMLArray(s)
 		}
-	case iohelpers.CSVMimetype:
-		out, err = CSV(s)
+	case iohelpers.CSVMimetype, iohelpers.TSVMimetype:
+		out, err = csvData(mimeType, s)
 	case iohelpers.TOMLMimetype:
 		out, err = TOML(s)
 	case iohelpers.EnvMimetype:
@@ -32,8 +45,109 @@ func ParseData(mimeType, s string) (out any, err error) {
 		out = s
 	case iohelpers.CUEMimetype:
 		out, err = CUE(s)
+	case iohelpers.XMLMimetype:
+		out, err = XML(s)
+	case iohelpers.HCLMimetype:
+		out, err = HCL(s)
+	case iohelpers.INIMimetype:
+		out, err = INI(s)
+	case iohelpers.PropertiesMimetype:
+		out, err = Properties(s)
+	case iohelpers.PlistMimetype:
+		out, err = Plist(s)
+	case iohelpers.MarkdownMimetype:
+		out, err = FrontMatter(s)
 	default:
 		return nil, fmt.Errorf("data of type %q not yet supported", mimeType)
 	}
 	return out, err
 }
+
+// Encode marshals the value into the format for the MIME type, with the
+// built-in encoder for that type
+func Encode(mimeType string, in any) (string, error) {
+	switch iohelpers.MimeAlias(mimeType) {
+	case iohelpers.JSONMimetype, iohelpers.JSONArrayMimetype:
+		return ToJSON(in)
+	case iohelpers.NDJSONMimetype:
+		return ToNDJSON(in)
+	case iohelpers.YAMLMimetype:
+		if isMultidoc(mimeType) {
+			return ToYAMLDocuments(in)
+		}
+		return ToYAML(in)
+	case iohelpers.TOMLMimetype:
+		return ToTOML(in)
+	case iohelpers.CSVMimetype:
+		return ToCSV(in)
+	case iohelpers.TSVMimetype:
+		return ToCSV("\t", in)
+	case iohelpers.CUEMimetype:
+		return ToCUE(in)
+	case iohelpers.XMLMimetype:
+		return ToXML(in)
+	case iohelpers.HCLMimetype:
+		return ToHCL(in)
+	case iohelpers.INIMimetype:
+		return ToINI(in)
+	case iohelpers.PropertiesMimetype:
+		return ToProperties(in)
+	case iohelpers.PlistMimetype:
+		return ToPlist(in)
+	case iohelpers.TextMimetype:
+		return conv.ToString(in), nil
+	default:
+		return "", fmt.Errorf("encoding data of type %q not yet supported", mimeType)
+	}
+}
+
+// csvData parses CSV or TSV data, as typed rows if the MIME type has a
+// "typed=true" parameter. The "delimiter", "quote", and "header" (present or
+// absent) parameters are also supported for typed data.
+func csvData(mimeType, s string) (any, error) {
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV type %q: %w", mimeType, err)
+	}
+
+	delim := ','
+	if iohelpers.MimeAlias(mimeType) == iohelpers.TSVMimetype {
+		delim = '\t'
+	}
+	if d, ok := params["delimiter"]; ok {
+		if utf8.RuneCountInString(d) != 1 {
+			return nil, fmt.Errorf("CSV delimiter must be a single character, got %q", d)
+		}
+		delim, _ = utf8.DecodeRuneInString(d)
+	}
+
+	if typed, _ := strconv.ParseBool(params["typed"]); !typed {
+		if delim == ',' {
+			return CSV(s)
+		}
+
+		return CSV(string(delim), s)
+	}
+
+	opts := CSVOptions{
+		Delimiter: delim,
+		NoHeader:  params["header"] == "absent",
+	}
+
+	if q, ok := params["quote"]; ok {
+		if utf8.RuneCountInString(q) != 1 {
+			return nil, fmt.Errorf("CSV quote must be a single character, got %q", q)
+		}
+		opts.Quote, _ = utf8.DecodeRuneInString(q)
+	}
+
+	return CSVTyped(s, opts)
+}
+
+// isMultidoc returns true if the MIME type has a "multidoc=true" parameter,
+// meaning that all documents in the stream should be parsed, not just the first
+func isMultidoc(mimeType string) bool {
+	_, params, _ := mime.ParseMediaType(mimeType)
+	multidoc, _ := strconv.ParseBool(params["multidoc"])
+	return multidoc
+}
@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseData_YAMLMultidoc(t *testing.T) {
+	in := "a: 1\n---\nb: 2\n"
+
+	out, err := ParseData("application/yaml", in)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, out)
+
+	out, err = ParseData("application/yaml; multidoc=true", in)
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"a": 1}, map[string]any{"b": 2}}, out)
+
+	out, err = ParseData("application/x-yaml;multidoc=true", in)
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"a": 1}, map[string]any{"b": 2}}, out)
+
+	out, err = ParseData("application/yaml; multidoc=false", in)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, out)
+}
+
+func TestParseData_CSV(t *testing.T) {
+	in := "name,age\nAlice,30\n"
+
+	out, err := ParseData("text/csv", in)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"Alice", "30"}}, out)
+
+	out, err = ParseData("text/csv; typed=true", in)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "Alice", "age": 30}}, out)
+
+	out, err = ParseData("text/tab-separated-values", "name\tage\nAlice\t30\n")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"Alice", "30"}}, out)
+
+	out, err = ParseData(`text/tab-separated-values; typed=true; header=absent; quote="'"`, "'Alice'\t30\n")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"A": "Alice", "B": 30}}, out)
+
+	out, err = ParseData(`text/csv; delimiter=";"`, "name;age\nAlice;30\n")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"Alice", "30"}}, out)
+
+	_, err = ParseData("text/csv; delimiter=ab", in)
+	require.Error(t, err)
+
+	_, err = ParseData("text/csv; delimiter=;", in)
+	require.Error(t, err)
+
+	_, err = ParseData("text/csv; typed=true; quote=ab", in)
+	require.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+
+	"howett.net/plist"
+)
+
+// Plist - Unmarshal an Apple property list (in XML or binary format) into an
+// object.
+//
+// Integers are converted to ints, <data> elements are []byte, and <date>
+// elements are time.Time values.
+func Plist(in string) (any, error) {
+	var out any
+
+	format, err := plist.Unmarshal([]byte(in), &out)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal plist: %w", err)
+	}
+
+	// the text formats are very permissive - almost any string is a valid
+	// OpenStep plist
+	if format != plist.XMLFormat && format != plist.BinaryFormat {
+		return nil, fmt.Errorf("unable to unmarshal plist: only XML and binary property lists are supported, not %s", plist.FormatNames[format])
+	}
+
+	return normalizePlistValue(out), nil
+}
+
+// normalizePlistValue converts the unsigned and 64-bit integers that the plist
+// decoder produces to ints, so they can be used like numbers from other formats
+func normalizePlistValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizePlistValue(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizePlistValue(e)
+		}
+	case uint64:
+		if v <= math.MaxInt {
+			return int(v)
+		}
+	case int64:
+		return int(v)
+	}
+
+	return v
+}
+
+// ToPlist - marshal an object into an XML property list. Null values are
+// omitted, since plists can't represent them.
+func ToPlist(in any) (string, error) {
+	b, err := plist.MarshalIndent(in, plist.XMLFormat, "\t")
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal plist: %w", err)
+	}
+
+	return string(b) + "\n", nil
+}
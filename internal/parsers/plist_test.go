@@ -0,0 +1,126 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"howett.net/plist"
+)
+
+func TestPlist(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.example.agent</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/usr/local/bin/agent</string>
+		<string>--verbose</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>StartInterval</key>
+	<integer>300</integer>
+	<key>Nice</key>
+	<integer>-5</integer>
+	<key>Ratio</key>
+	<real>0.5</real>
+	<key>Token</key>
+	<data>aGVsbG8=</data>
+	<key>Since</key>
+	<date>2024-01-02T03:04:05Z</date>
+</dict>
+</plist>
+`
+	expected := map[string]any{
+		"Label":            "com.example.agent",
+		"ProgramArguments": []any{"/usr/local/bin/agent", "--verbose"},
+		"RunAtLoad":        true,
+		"StartInterval":    300,
+		"Nice":             -5,
+		"Ratio":            0.5,
+		"Token":            []byte("hello"),
+		"Since":            time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	out, err := Plist(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// binary plists are supported too
+	b, err := plist.Marshal(map[string]any{
+		"CFBundleName":    "App",
+		"CFBundleVersion": uint64(42),
+		"Nested":          map[string]any{"a": []any{1, "b"}},
+	}, plist.BinaryFormat)
+	require.NoError(t, err)
+
+	out, err = Plist(string(b))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"CFBundleName":    "App",
+		"CFBundleVersion": 42,
+		"Nested":          map[string]any{"a": []any{1, "b"}},
+	}, out)
+
+	// the root doesn't need to be a dict
+	out, err = Plist(`<plist version="1.0"><array><string>a</string></array></plist>`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a"}, out)
+
+	// text formats aren't supported
+	_, err = Plist(`{ Label = "com.example.agent"; }`)
+	require.Error(t, err)
+
+	_, err = Plist("hello world")
+	require.Error(t, err)
+
+	_, err = Plist(`<plist version="1.0"><dict><key>a</key></plist>`)
+	require.Error(t, err)
+}
+
+func TestToPlist(t *testing.T) {
+	in := map[string]any{
+		"Label":            "com.example.agent",
+		"ProgramArguments": []any{"/usr/local/bin/agent", "--verbose"},
+		"RunAtLoad":        true,
+		"StartInterval":    300,
+		"Ignored":          nil,
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+	<dict>
+		<key>Label</key>
+		<string>com.example.agent</string>
+		<key>ProgramArguments</key>
+		<array>
+			<string>/usr/local/bin/agent</string>
+			<string>--verbose</string>
+		</array>
+		<key>RunAtLoad</key>
+		<true/>
+		<key>StartInterval</key>
+		<integer>300</integer>
+	</dict>
+</plist>
+`
+
+	out, err := ToPlist(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	parsed, err := Plist(out)
+	require.NoError(t, err)
+	delete(in, "Ignored")
+	assert.Equal(t, in, parsed)
+
+	_, err = ToPlist(map[int]any{1: "a"})
+	require.Error(t, err)
+}
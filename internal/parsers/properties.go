@@ -0,0 +1,257 @@
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/hairyhenderson/gomplate/v5/conv"
+)
+
+// Properties - Unmarshal a Java .properties file into a flat object, following
+// the same rules as Java's Properties.load. Keys are separated from values by
+// '=', ':' or whitespace, lines ending with an odd number of backslashes are
+// continued on the next line, and escapes (including \uXXXX) are decoded.
+// Lines starting with '#' or '!' are comments. Dotted keys (e.g. `server.port`)
+// are not nested.
+func Properties(in string) (map[string]any, error) {
+	obj := map[string]any{}
+
+	s := bufio.NewScanner(strings.NewReader(in))
+	lineNum := 0
+
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimLeft(s.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for propsContinued(line) && s.Scan() {
+			lineNum++
+			line = line[:len(line)-1] + strings.TrimLeft(s.Text(), " \t\f")
+		}
+
+		k, v := propsSplit(line)
+
+		key, err := propsUnescape(k)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal properties: line %d: %w", lineNum, err)
+		}
+
+		val, err := propsUnescape(v)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal properties: line %d: %w", lineNum, err)
+		}
+
+		obj[key] = val
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal properties: %w", err)
+	}
+
+	return obj, nil
+}
+
+// propsContinued returns true if the line ends with an unescaped backslash
+func propsContinued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// propsSplit splits a logical line into its (still-escaped) key and value
+func propsSplit(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	k := line[:end]
+	v := strings.TrimLeft(line[end:], " \t\f")
+	if v != "" && (v[0] == '=' || v[0] == ':') {
+		v = strings.TrimLeft(v[1:], " \t\f")
+	}
+
+	return k, v
+}
+
+func propsUnescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' || i == len(runes)-1 {
+			out = append(out, runes[i])
+			continue
+		}
+
+		i++
+		switch runes[i] {
+		case 't':
+			out = append(out, '\t')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 'f':
+			out = append(out, '\f')
+		case 'u':
+			if i+5 > len(runes) {
+				return "", fmt.Errorf("malformed \\uXXXX escape %q", string(runes[i-1:]))
+			}
+
+			u, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX escape %q", string(runes[i-1:i+5]))
+			}
+
+			r := rune(u)
+
+			// \uXXXX escapes are UTF-16, so surrogate pairs must be combined
+			if n := len(out); n > 0 && utf16.IsSurrogate(out[n-1]) && utf16.IsSurrogate(r) {
+				if pair := utf16.DecodeRune(out[n-1], r); pair != unicode.ReplacementChar {
+					out[n-1] = pair
+					i += 4
+
+					continue
+				}
+			}
+
+			out = append(out, r)
+			i += 4
+		default:
+			out = append(out, runes[i])
+		}
+	}
+
+	return string(out), nil
+}
+
+// ToProperties - Stringify an object as a Java .properties file. Nested objects
+// are flattened with dotted keys, and arrays with indexed keys (e.g.
+// `servers[0]`), as used by Spring. Keys are sorted, and characters that
+// aren't printable ASCII are written as \uXXXX escapes.
+func ToProperties(in any) (string, error) {
+	rv := reflect.ValueOf(in)
+	if rv.Kind() != reflect.Map {
+		return "", fmt.Errorf("unable to marshal properties: expected an object, got %T", in)
+	}
+
+	flat := map[string]string{}
+	propsFlatten(flat, "", in)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	sb := &strings.Builder{}
+	for _, k := range keys {
+		sb.WriteString(propsEscape(k, true))
+		sb.WriteString("=")
+		sb.WriteString(propsEscape(flat[k], false))
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+func propsFlatten(flat map[string]string, prefix string, v any) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		for iter := rv.MapRange(); iter.Next(); {
+			k := conv.ToString(iter.Key().Interface())
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+
+			propsFlatten(flat, k, iter.Value().Interface())
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is text, not an array
+			flat[prefix] = string(rv.Bytes())
+			return
+		}
+
+		for i := range rv.Len() {
+			propsFlatten(flat, fmt.Sprintf("%s[%d]", prefix, i), rv.Index(i).Interface())
+		}
+	case reflect.Invalid, reflect.Interface, reflect.Pointer:
+		flat[prefix] = ""
+	default:
+		flat[prefix] = conv.ToString(rv.Interface())
+	}
+}
+
+// propsEscape escapes s so that Properties reads it back unchanged. In keys,
+// separators and comment characters must also be escaped.
+func propsEscape(s string, isKey bool) string {
+	sb := &strings.Builder{}
+
+	for i, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			if isKey {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		case ' ':
+			// leading spaces in values would be skipped
+			if isKey || i == 0 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, u := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(sb, `\u%04X`, u)
+				}
+
+				continue
+			}
+
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProperties(t *testing.T) {
+	in := `# Spring config
+! also a comment
+server.port=8080
+spring.application.name : my-app
+greeting Hello, World
+  indented.key = indented value
+empty=
+fruits = apple, banana, \
+         pear, \
+         cantaloupe
+key\ with\ spaces = value
+a\=b\:c = d
+unicode = caf\u00e9 \uD83D\uDE00
+escapes = tab\there\nnewline\\backslash\q
+path=c:\\temp\\
+trailing = spaces  
+`
+	expected := map[string]any{
+		"server.port":             "8080",
+		"spring.application.name": "my-app",
+		"greeting":                "Hello, World",
+		"indented.key":            "indented value",
+		"empty":                   "",
+		"fruits":                  "apple, banana, pear, cantaloupe",
+		"key with spaces":         "value",
+		"a=b:c":                   "d",
+		"unicode":                 "café 😀",
+		"escapes":                 "tab\there\nnewline\\backslashq",
+		"path":                    `c:\temp\`,
+		"trailing":                "spaces  ",
+	}
+
+	out, err := Properties(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	out, err = Properties("keyonly\nfoo=1\nfoo=2\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"keyonly": "", "foo": "2"}, out)
+
+	_, err = Properties(`bad = \u12`)
+	require.Error(t, err)
+
+	_, err = Properties(`bad = \uZZZZ`)
+	require.Error(t, err)
+}
+
+func TestToProperties(t *testing.T) {
+	in := map[string]any{
+		"server": map[string]any{
+			"port":    8080,
+			"address": "0.0.0.0",
+		},
+		"hosts":           []any{"a.example.com", "b.example.com"},
+		"key with spaces": "value",
+		"a=b:c":           " leading space",
+		"unicode":         "café 😀",
+		"multi":           "line 1\nline 2",
+		"path":            `c:\temp`,
+		"nothing":         nil,
+	}
+
+	expected := `a\=b\:c=\ leading space
+hosts[0]=a.example.com
+hosts[1]=b.example.com
+key\ with\ spaces=value
+multi=line 1\nline 2
+nothing=
+path=c:\\temp
+server.address=0.0.0.0
+server.port=8080
+unicode=caf\u00E9 \uD83D\uDE00
+`
+
+	out, err := ToProperties(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip
+	parsed, err := Properties(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"server.address":  "0.0.0.0",
+		"server.port":     "8080",
+		"hosts[0]":        "a.example.com",
+		"hosts[1]":        "b.example.com",
+		"key with spaces": "value",
+		"a=b:c":           " leading space",
+		"unicode":         "café 😀",
+		"multi":           "line 1\nline 2",
+		"path":            `c:\temp`,
+		"nothing":         "",
+	}, parsed)
+
+	_, err = ToProperties("foo")
+	require.Error(t, err)
+}
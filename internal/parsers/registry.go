@@ -0,0 +1,126 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/iohelpers"
+)
+
+// Parser - a parse function, and optionally an encoder, for a custom data
+// format
+type Parser struct {
+	// Parse - parses data in this format into a value that can be used in
+	// templates (usually a map[string]any or []any). Required.
+	Parse func(in []byte) (any, error)
+
+	// Encode - marshals a value into this format. Optional - without it,
+	// values can't be encoded to this format with data.Encode.
+	Encode func(in any) ([]byte, error)
+
+	// Extensions - file extensions (e.g. ".foo") to detect as this format
+	Extensions []string
+}
+
+// Registry - a set of custom parsers, by MIME type. Custom parsers take
+// precedence over the built-in ones, so built-in types can be overridden. A nil
+// *Registry is valid, and has no custom parsers.
+type Registry struct {
+	parsers    map[string]Parser
+	extensions map[string]string
+}
+
+// NewRegistry creates a registry from a map of parsers, keyed by MIME type
+func NewRegistry(parsers map[string]Parser) *Registry {
+	r := &Registry{
+		parsers:    make(map[string]Parser, len(parsers)),
+		extensions: map[string]string{},
+	}
+
+	for mimeType, p := range parsers {
+		mimeType = iohelpers.MimeAlias(mimeType)
+		r.parsers[mimeType] = p
+
+		for _, ext := range p.Extensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			r.extensions[ext] = mimeType
+		}
+	}
+
+	return r
+}
+
+func (r *Registry) lookup(mimeType string) (Parser, bool) {
+	if r == nil {
+		return Parser{}, false
+	}
+
+	p, ok := r.parsers[iohelpers.MimeAlias(mimeType)]
+	return p, ok
+}
+
+// ParseData parses the data with the custom parser for the MIME type, or with
+// a built-in parser if there's no custom one (see [ParseData])
+func (r *Registry) ParseData(mimeType, s string) (any, error) {
+	p, ok := r.lookup(mimeType)
+	if !ok {
+		return ParseData(mimeType, s)
+	}
+
+	if p.Parse == nil {
+		return nil, fmt.Errorf("no parse function registered for data of type %q", mimeType)
+	}
+
+	return p.Parse([]byte(s))
+}
+
+// Encode marshals the value with the custom encoder for the MIME type, or with
+// a built-in encoder if there's no custom parser for the type (see [Encode])
+func (r *Registry) Encode(mimeType string, in any) (string, error) {
+	p, ok := r.lookup(mimeType)
+	if !ok {
+		return Encode(mimeType, in)
+	}
+
+	if p.Encode == nil {
+		return "", fmt.Errorf("no encoder registered for data of type %q", mimeType)
+	}
+
+	b, err := p.Encode(in)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// MimeTypeByExtension returns the MIME type registered for the file extension
+// (including the leading dot), or "" if the extension isn't registered
+func (r *Registry) MimeTypeByExtension(ext string) string {
+	if r == nil {
+		return ""
+	}
+
+	return r.extensions[strings.ToLower(ext)]
+}
+
+type registryCtxKey struct{}
+
+// ContextWithRegistry returns a context with the given parser registry
+func ContextWithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryCtxKey{}, r)
+}
+
+// RegistryFromContext returns the parser registry from the context, or nil if
+// there isn't one (which is still usable, and has no custom parsers)
+func RegistryFromContext(ctx context.Context) *Registry {
+	if r, ok := ctx.Value(registryCtxKey{}).(*Registry); ok {
+		return r
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(map[string]Parser{
+		"application/x-upper": {
+			Parse: func(in []byte) (any, error) {
+				return strings.ToUpper(string(in)), nil
+			},
+			Encode: func(in any) ([]byte, error) {
+				return []byte(strings.ToLower(fmt.Sprint(in))), nil
+			},
+			Extensions: []string{".UP", "upper"},
+		},
+		"application/x-noencode": {
+			Parse: func(in []byte) (any, error) {
+				return string(in), nil
+			},
+		},
+		"application/x-noparse": {},
+		// overrides the built-in JSON parser, including for aliases
+		"application/json": {
+			Parse: func(_ []byte) (any, error) {
+				return "custom", nil
+			},
+		},
+	})
+
+	out, err := r.ParseData("application/x-upper", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", out)
+
+	out, err = r.ParseData("application/x-upper; charset=utf-8", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", out)
+
+	out, err = r.ParseData("application/json", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", out)
+
+	out, err = r.ParseData("application/yaml", "a: 1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, out)
+
+	_, err = r.ParseData("application/x-noparse", "hello")
+	require.Error(t, err)
+
+	s, err := r.Encode("application/x-upper", "HELLO")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", s)
+
+	s, err = r.Encode("application/yaml", map[string]any{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1\n", s)
+
+	_, err = r.Encode("application/x-noencode", "hello")
+	require.Error(t, err)
+
+	assert.Equal(t, "application/x-upper", r.MimeTypeByExtension(".up"))
+	assert.Equal(t, "application/x-upper", r.MimeTypeByExtension(".Upper"))
+	assert.Empty(t, r.MimeTypeByExtension(".json"))
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var r *Registry
+
+	out, err := r.ParseData("application/json", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, out)
+
+	s, err := r.Encode("application/json", map[string]any{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, s)
+
+	assert.Empty(t, r.MimeTypeByExtension(".json"))
+}
+
+func TestRegistryFromContext(t *testing.T) {
+	assert.Nil(t, RegistryFromContext(context.Background()))
+
+	r := NewRegistry(nil)
+	ctx := ContextWithRegistry(context.Background(), r)
+	assert.Same(t, r, RegistryFromContext(ctx))
+}
+
+func TestEncode(t *testing.T) {
+	testdata := []struct {
+		in       any
+		mimeType string
+		expected string
+	}{
+		{map[string]any{"a": 1}, "application/json", `{"a":1}`},
+		{[]any{1, 2}, "application/array+json", `[1,2]`},
+		{[]any{1, 2}, "application/x-ndjson", "1\n2\n"},
+		{map[string]any{"a": 1}, "application/yaml", "a: 1\n"},
+		{[]any{map[string]any{"a": 1}, map[string]any{"b": 2}}, "application/yaml; multidoc=true", "a: 1\n---\nb: 2\n"},
+		{map[string]any{"a": 1}, "application/toml", "a = 1\n"},
+		{[][]string{{"a", "b"}, {"1", "2"}}, "text/csv", "a,b\r\n1,2\r\n"},
+		{[][]string{{"a", "b"}, {"1", "2"}}, "text/tab-separated-values", "a\tb\r\n1\t2\r\n"},
+		{map[string]any{"a": "b"}, "text/x-ini", "a = b\n"},
+		{map[string]any{"a": "b"}, "text/x-java-properties", "a=b\n"},
+		{42, "text/plain", "42"},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.mimeType, func(t *testing.T) {
+			out, err := Encode(d.mimeType, d.in)
+			require.NoError(t, err)
+			assert.Equal(t, d.expected, out)
+		})
+	}
+
+	_, err := Encode("application/x-unknown", "foo")
+	require.Error(t, err)
+}
@@ -0,0 +1,281 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v5/conv"
+)
+
+// XML element and attribute names are mapped to keys with these conventions:
+//   - attributes are keys prefixed with "@"
+//   - the text content of elements with attributes or child elements is in the
+//     "#text" key
+//   - elements with only text content are strings, and empty elements without
+//     attributes are empty strings
+//   - repeated child elements are arrays
+//   - namespace prefixes are kept as written (e.g. "soap:Envelope"), and
+//     namespace declarations are attributes (e.g. "@xmlns:soap")
+//
+// Text is trimmed of surrounding whitespace, and comments, processing
+// instructions and directives are ignored.
+const (
+	xmlAttrPrefix = "@"
+	xmlTextKey    = "#text"
+)
+
+// XML - Unmarshal an XML document into an object with a single key - the root
+// element's name
+func XML(in string) (map[string]any, error) {
+	dec := xml.NewDecoder(strings.NewReader(in))
+
+	var root map[string]any
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil {
+				return nil, fmt.Errorf("unable to unmarshal XML: multiple root elements")
+			}
+
+			v, err := xmlElement(dec, t)
+			if err != nil {
+				return nil, fmt.Errorf("unable to unmarshal XML: %w", err)
+			}
+
+			root = map[string]any{xmlName(t.Name): v}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("unable to unmarshal XML: text outside of root element")
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("unable to unmarshal XML: no root element")
+	}
+
+	return root, nil
+}
+
+// xmlElement reads the content of the element started by start, up to and
+// including its end element
+func xmlElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	obj := map[string]any{}
+	for _, a := range start.Attr {
+		obj[xmlAttrPrefix+xmlName(a.Name)] = a.Value
+	}
+
+	text := &strings.Builder{}
+	hasChildren := false
+
+	for {
+		// raw tokens are used so that namespace prefixes are kept, so nesting
+		// must be checked here
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("element <%s> not closed", xmlName(start.Name))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			hasChildren = true
+
+			v, err := xmlElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+
+			name := xmlName(t.Name)
+			switch existing := obj[name].(type) {
+			case nil:
+				obj[name] = v
+			case []any:
+				obj[name] = append(existing, v)
+			default:
+				obj[name] = []any{existing, v}
+			}
+		case xml.EndElement:
+			if t.Name != start.Name {
+				return nil, fmt.Errorf("element <%s> closed by </%s>", xmlName(start.Name), xmlName(t.Name))
+			}
+
+			s := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return s, nil
+			}
+
+			if s != "" {
+				obj[xmlTextKey] = s
+			}
+
+			return obj, nil
+		case xml.CharData:
+			// whitespace between child elements isn't content
+			if !hasChildren || len(bytes.TrimSpace(t)) > 0 {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+
+	return n.Space + ":" + n.Local
+}
+
+// ToXML - Stringify an object as an XML document. The object must have a
+// single key, which is used as the root element's name. The same conventions
+// as for XML are used, so that documents can be round-tripped.
+func ToXML(in any) (string, error) {
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Map || v.Len() != 1 {
+		return "", fmt.Errorf("unable to marshal XML: expected an object with a single key (the root element), got %T", in)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+
+	iter := v.MapRange()
+	iter.Next()
+
+	name := conv.ToString(iter.Key().Interface())
+	if strings.HasPrefix(name, xmlAttrPrefix) || name == xmlTextKey {
+		return "", fmt.Errorf("unable to marshal XML: root element name %q is not valid", name)
+	}
+
+	err := xmlEncode(enc, name, iter.Value().Interface())
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal XML: %w", err)
+	}
+
+	err = enc.Flush()
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal XML: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// xmlEncode encodes v as one element with the given name, or as repeated
+// elements if v is an array
+func xmlEncode(enc *xml.Encoder, name string, v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is text, not an array
+			return xmlEncodeText(enc, start, string(rv.Bytes()))
+		}
+
+		for i := range rv.Len() {
+			err := xmlEncode(enc, name, rv.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		return xmlEncodeObject(enc, start, rv)
+	case reflect.Invalid:
+		return xmlEncodeText(enc, start, "")
+	default:
+		return xmlEncodeText(enc, start, conv.ToString(rv.Interface()))
+	}
+}
+
+func xmlEncodeObject(enc *xml.Encoder, start xml.StartElement, rv reflect.Value) error {
+	keys := make([]string, 0, rv.Len())
+	values := make(map[string]any, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := conv.ToString(iter.Key().Interface())
+		keys = append(keys, k)
+		values[k] = iter.Value().Interface()
+	}
+
+	// sorted, so that output is stable
+	slices.Sort(keys)
+
+	children := []string{}
+	for _, k := range keys {
+		switch {
+		case strings.HasPrefix(k, xmlAttrPrefix):
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(k, xmlAttrPrefix)},
+				Value: conv.ToString(values[k]),
+			})
+		case k != xmlTextKey:
+			children = append(children, k)
+		}
+	}
+
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	if text, ok := values[xmlTextKey]; ok {
+		err = enc.EncodeToken(xml.CharData(conv.ToString(text)))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, k := range children {
+		err = xmlEncode(enc, k, values[k])
+		if err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func xmlEncodeText(enc *xml.Encoder, start xml.StartElement, text string) error {
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	if text != "" {
+		err = enc.EncodeToken(xml.CharData(text))
+		if err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
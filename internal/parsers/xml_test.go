@@ -0,0 +1,126 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXML(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- a Maven POM -->
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <artifactId>demo</artifactId>
+  <properties/>
+  <dependencies>
+    <dependency scope="test">
+      <artifactId>junit</artifactId>
+    </dependency>
+    <dependency>
+      <artifactId>guava</artifactId>
+    </dependency>
+  </dependencies>
+  <description lang="en"> A <![CDATA[<demo>]]> project </description>
+</project>
+`
+	expected := map[string]any{
+		"project": map[string]any{
+			"@xmlns":       "http://maven.apache.org/POM/4.0.0",
+			"modelVersion": "4.0.0",
+			"artifactId":   "demo",
+			"properties":   "",
+			"dependencies": map[string]any{
+				"dependency": []any{
+					map[string]any{"@scope": "test", "artifactId": "junit"},
+					map[string]any{"artifactId": "guava"},
+				},
+			},
+			"description": map[string]any{"@lang": "en", "#text": "A <demo> project"},
+		},
+	}
+
+	out, err := XML(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// namespace prefixes are kept
+	out, err = XML(`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>hi</soap:Body></soap:Envelope>`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"soap:Envelope": map[string]any{
+			"@xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+			"soap:Body":   "hi",
+		},
+	}, out)
+
+	for _, bad := range []string{
+		``,
+		`<a>`,
+		`<a></b>`,
+		`<a/><b/>`,
+		`text<a/>`,
+		`<a><b></a>`,
+	} {
+		_, err = XML(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestToXML(t *testing.T) {
+	in := map[string]any{
+		"project": map[string]any{
+			"@xmlns":       "http://maven.apache.org/POM/4.0.0",
+			"modelVersion": "4.0.0",
+			"version":      1,
+			"properties":   "",
+			"dependencies": map[string]any{
+				"dependency": []any{
+					map[string]any{"@scope": "test", "artifactId": "junit"},
+					map[string]any{"artifactId": "a&b"},
+				},
+			},
+			"description": map[string]any{"@lang": "en", "#text": "A <demo> project"},
+		},
+	}
+
+	expected := `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <dependencies>
+    <dependency scope="test">
+      <artifactId>junit</artifactId>
+    </dependency>
+    <dependency>
+      <artifactId>a&amp;b</artifactId>
+    </dependency>
+  </dependencies>
+  <description lang="en">A &lt;demo&gt; project</description>
+  <modelVersion>4.0.0</modelVersion>
+  <properties></properties>
+  <version>1</version>
+</project>`
+
+	out, err := ToXML(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// round-trip - values are always strings when parsed
+	parsed, err := XML(out)
+	require.NoError(t, err)
+	in["project"].(map[string]any)["version"] = "1"
+	in["project"].(map[string]any)["dependencies"].(map[string]any)["dependency"].([]any)[1] = map[string]any{"artifactId": "a&b"}
+	assert.Equal(t, in, parsed)
+
+	out, err = ToXML(map[string]string{"greeting": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "<greeting>hello</greeting>", out)
+
+	_, err = ToXML(map[string]any{"a": 1, "b": 2})
+	require.Error(t, err)
+
+	_, err = ToXML([]any{"a"})
+	require.Error(t, err)
+
+	_, err = ToXML(map[string]any{"@a": 1})
+	require.Error(t, err)
+}
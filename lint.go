@@ -0,0 +1,438 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/hairyhenderson/gomplate/v5/internal/deprecated"
+	"github.com/hairyhenderson/gomplate/v5/internal/funcs"
+)
+
+// Lint issue severities
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue - a problem found in a template by [Lint]
+//
+// Experimental: subject to breaking changes before the next major release
+type LintIssue struct {
+	// Template - the template file, or "<arg>" for templates given with the
+	// 'in' option
+	Template string `json:"template"`
+	// Line and Column - the 1-based position of the problem, or 0 when
+	// unknown
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+	// Severity - LintError for problems that will fail rendering, and
+	// LintWarning for the rest
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	loc := i.Template
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", loc, i.Line, i.Column)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", loc, i.Severity, i.Message)
+}
+
+// builtinFuncs - the functions predefined by text/template
+var builtinFuncs = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or", "print",
+	"printf", "println", "urlquery", "eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// Lint parses all templates specified by the given configuration, as well as
+// local nested templates, and reports problems that would otherwise only be
+// found when rendering:
+//   - unknown functions
+//   - datasource aliases that aren't defined
+//   - experimental functions, when experimental mode isn't enabled
+//   - deprecated functions
+//   - nested templates that don't exist
+//
+// Templates are not rendered, and nothing is written. An error is only
+// returned when the templates can't be gathered - problems in the templates
+// themselves (including parse errors) are returned as issues.
+//
+// Experimental: subject to breaking changes before the next major release
+func Lint(ctx context.Context, cfg *Config) ([]LintIssue, error) {
+	a, tmpls, _, err := newDepsAnalyzer(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{
+		depsAnalyzer: a,
+		funcs:        CreateFuncs(a.ctx),
+		nested:       map[string]bool{},
+	}
+
+	// datasource funcs are added when rendering, since they need the source
+	// reader - they aren't called here, so none is needed
+	maps.Copy(l.funcs, funcs.CreateDataSourceFuncs(a.ctx, nil))
+
+	for _, name := range builtinFuncs {
+		l.funcs[name] = nil
+	}
+	for name := range cfg.Plugins {
+		l.funcs[name] = nil
+	}
+	addTmplFuncs(l.funcs, template.New(""), nil, "")
+
+	nested, err := l.loadNested()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tmpls {
+		l.lint(t.Name, t.Text)
+	}
+
+	for _, t := range nested {
+		l.lint(t.Name, t.Text)
+	}
+
+	return l.issues, nil
+}
+
+// linter checks templates by walking their parse trees
+type linter struct {
+	*depsAnalyzer
+
+	funcs template.FuncMap
+	// names of the templates defined in nested template files - only valid
+	// when allNested is set
+	nested    map[string]bool
+	allNested bool
+
+	issues []LintIssue
+}
+
+// lintRef - a literal reference to a datasource or template, which can only be
+// checked once the whole template has been walked
+type lintRef struct {
+	name string
+	pos  parse.Pos
+}
+
+// lintFile holds the state for the template file being linted
+type lintFile struct {
+	name string
+	text string
+
+	datasources []lintRef
+	templates   []lintRef
+	// datasources defined in the template with defineDatasource
+	defined map[string]string
+}
+
+// loadNested reads and parses the local nested templates, recording the
+// names of the templates they define, and returns them for linting. When some
+// nested templates aren't local, their names are unknown, and references to
+// nested templates can't be checked.
+func (l *linter) loadNested() ([]Template, error) {
+	l.allNested = true
+
+	tmpls := []Template{}
+	for _, alias := range sortedKeys(l.cfg.Templates) {
+		p, ok := localPath(l.cfg.Templates[alias].URL)
+		if !ok {
+			l.allNested = false
+			continue
+		}
+
+		fsys, err := datafs.FSysForPath(l.ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("fsysForPath: %w", err)
+		}
+
+		fi, err := fs.Stat(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("stat nested template %q: %w", p, err)
+		}
+
+		files := map[string]string{alias: p}
+		if fi.IsDir() {
+			files = map[string]string{}
+
+			entries, err := fs.ReadDir(fsys, p)
+			if err != nil {
+				return nil, fmt.Errorf("readDir %q: %w", p, err)
+			}
+
+			for _, e := range entries {
+				if !e.IsDir() {
+					files[path.Join(alias, e.Name())] = path.Join(p, e.Name())
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(files) {
+			b, err := fs.ReadFile(fsys, files[name])
+			if err != nil {
+				return nil, fmt.Errorf("readFile %q: %w", files[name], err)
+			}
+
+			l.nested[name] = true
+
+			// parse errors are reported when the file is linted
+			trees, _ := l.parseTrees(files[name], string(b))
+			for t := range trees {
+				l.nested[t] = true
+			}
+
+			tmpls = append(tmpls, Template{Name: files[name], Text: string(b)})
+		}
+	}
+
+	return tmpls, nil
+}
+
+func (l *linter) parseTrees(name, text string) (map[string]*parse.Tree, error) {
+	// functions are checked separately, so that all unknown functions can be
+	// reported, with their positions
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+
+	trees := map[string]*parse.Tree{}
+	_, err := t.Parse(text, l.cfg.LDelim, l.cfg.RDelim, trees)
+
+	return trees, err
+}
+
+// lint checks a single template file
+func (l *linter) lint(name, text string) {
+	trees, err := l.parseTrees(name, text)
+	if err != nil {
+		l.issues = append(l.issues, LintIssue{
+			Template: name,
+			Severity: LintError,
+			Message:  err.Error(),
+		})
+
+		return
+	}
+
+	start := len(l.issues)
+
+	f := &lintFile{name: name, text: text, defined: map[string]string{}}
+	for _, tname := range sortedKeys(trees) {
+		l.walk(f, trees[tname].Root, nil)
+	}
+
+	for _, ref := range f.datasources {
+		// datasourceURL only needs the datasources defined in the template
+		if l.datasourceURL(&depsSet{defined: f.defined}, ref.name, false) == nil {
+			l.report(f, ref.pos, LintError, "undefined datasource %q", ref.name)
+		}
+	}
+
+	if l.allNested {
+		for _, ref := range f.templates {
+			if _, ok := trees[ref.name]; !ok && !l.nested[ref.name] {
+				l.report(f, ref.pos, LintError, "undefined template %q", ref.name)
+			}
+		}
+	}
+
+	slices.SortStableFunc(l.issues[start:], func(a, b LintIssue) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+}
+
+// report records an issue at the given position in the file
+func (l *linter) report(f *lintFile, pos parse.Pos, severity, format string, args ...any) {
+	before := f.text[:min(int(pos), len(f.text))]
+	line := 1 + strings.Count(before, "\n")
+	col := len(before) - strings.LastIndex(before, "\n")
+
+	l.issues = append(l.issues, LintIssue{
+		Template: f.name,
+		Line:     line,
+		Column:   col,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// walk checks node and all of its children. The piped argument is the literal
+// string piped into a command, if any.
+func (l *linter) walk(f *lintFile, node parse.Node, piped parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			l.walk(f, c, nil)
+		}
+	case *parse.ActionNode:
+		l.walk(f, n.Pipe, nil)
+	case *parse.IfNode:
+		l.walkBranch(f, &n.BranchNode)
+	case *parse.RangeNode:
+		l.walkBranch(f, &n.BranchNode)
+	case *parse.WithNode:
+		l.walkBranch(f, &n.BranchNode)
+	case *parse.TemplateNode:
+		f.templates = append(f.templates, lintRef{name: n.Name, pos: n.Pos})
+		l.walk(f, n.Pipe, nil)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		var prev parse.Node
+		for _, c := range n.Cmds {
+			l.walk(f, c, prev)
+
+			prev = nil
+			if len(c.Args) == 1 && c.Args[0].Type() == parse.NodeString {
+				prev = c.Args[0]
+			}
+		}
+	case *parse.CommandNode:
+		l.command(f, n, piped)
+		for _, arg := range n.Args {
+			l.walk(f, arg, nil)
+		}
+	case *parse.IdentifierNode:
+		l.function(f, n.Ident, nil, n.Pos)
+	case *parse.ChainNode:
+		if id, ok := n.Node.(*parse.IdentifierNode); ok {
+			l.function(f, id.Ident, n.Field, id.Pos)
+			return
+		}
+		l.walk(f, n.Node, nil)
+	}
+}
+
+func (l *linter) walkBranch(f *lintFile, n *parse.BranchNode) {
+	l.walk(f, n.Pipe, nil)
+	l.walk(f, n.List, nil)
+	l.walk(f, n.ElseList, nil)
+}
+
+// command records the datasource and template references made by the
+// command, when they're literal strings
+func (l *linter) command(f *lintFile, cmd *parse.CommandNode, piped parse.Node) {
+	args := slices.Clone(cmd.Args[1:])
+	if piped != nil {
+		args = append(args, piped)
+	}
+
+	arg := func(i int) (string, bool) {
+		if i < len(args) {
+			if sn, ok := args[i].(*parse.StringNode); ok {
+				return sn.Text, true
+			}
+		}
+		return "", false
+	}
+
+	switch funcName(cmd.Args[0]) {
+	// datasourceExists is meant to be used with undefined datasources
+	case "datasource", "ds", "datasourceReachable", "include":
+		if alias, ok := arg(0); ok {
+			f.datasources = append(f.datasources, lintRef{name: alias, pos: cmd.Pos})
+		}
+	case "defineDatasource":
+		alias, ok := arg(0)
+		if !ok {
+			return
+		}
+		if u, ok := arg(1); ok {
+			f.defined[alias] = u
+		}
+	case "tmpl.Exec":
+		if name, ok := arg(0); ok {
+			f.templates = append(f.templates, lintRef{name: name, pos: cmd.Pos})
+		}
+	}
+}
+
+// function checks a call of the named function, with the given chained
+// fields (e.g. "strings" and "ToUpper" for strings.ToUpper)
+func (l *linter) function(f *lintFile, name string, fields []string, pos parse.Pos) {
+	fn, ok := l.funcs[name]
+	if !ok {
+		l.report(f, pos, LintError, "unknown function %q", name)
+		return
+	}
+
+	if len(fields) > 0 && !hasMember(fn, fields[0]) {
+		l.report(f, pos, LintError, "unknown function %q", name+"."+fields[0])
+		return
+	}
+
+	if len(fields) > 0 {
+		name += "." + fields[0]
+	}
+
+	if funcs.IsExperimental(name) && !l.cfg.Experimental {
+		l.report(f, pos, LintError, "%s is experimental, and experimental mode is not enabled", name)
+	}
+
+	if msg, ok := deprecated.Functions[name]; ok {
+		l.report(f, pos, LintWarning, "%s", msg)
+	}
+}
+
+// hasMember reports whether the value returned by the function fn has a method
+// or field with the given name. Namespace functions (like "strings") are
+// called to find the namespace, but other functions are never called, so if
+// their result type isn't known this returns true.
+func hasMember(fn any, name string) bool {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.Type().NumIn() != 0 || v.Type().NumOut() == 0 {
+		return true
+	}
+
+	t := v.Type().Out(0)
+	if t.Kind() == reflect.Interface {
+		if t.NumMethod() != 0 {
+			return true
+		}
+
+		// namespace functions return the namespace as an any
+		out := v.Call(nil)[0]
+		if out.IsNil() {
+			return true
+		}
+		t = out.Elem().Type()
+	}
+
+	if _, ok := t.MethodByName(name); ok {
+		return true
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		_, ok := t.FieldByName(name)
+		return ok
+	case reflect.Map, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+package gomplate
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	// chdir to root so we can use relative paths
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys, _ := mem.NewFS()
+
+	_ = hackpadfs.MkdirAll(fsys, "in", 0o777)
+	_ = hackpadfs.MkdirAll(fsys, "tpl", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "in/ok.tmpl", []byte(`{{ define "local" }}{{ . | strings.ToUpper }}{{ end -}}
+{{ template "local" (ds "config").name }}
+{{ template "tpl/nested.tmpl" }}{{ template "shared" }}
+{{ "inc" | include }}{{ defineDatasource "other" "other.json" }}{{ ds "other" }}
+{{ if datasourceExists "maybe" }}{{ ds "https://example.com/data.json" }}{{ end }}
+{{ time.Now.Year }} {{ len "foo" | printf "%d" }} {{ tmpl.Exec "local" }}
+{{ shout "hi" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/bad.tmpl", []byte(`{{ nope }}
+  {{ strings.Nope "a" }}{{ ds "missing" }}
+{{ template "undefined" }}{{ net.CIDRHost 1 "10.0.0.0/8" }}
+{{ crypto.EncryptAES "key" "text" }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/broken.tmpl", []byte(`{{ if }}`), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "tpl/nested.tmpl", []byte(`{{ define "shared" }}{{ bogus }}{{ end }}`), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	cfg := &Config{
+		InputDir:  "in",
+		OutputDir: "out",
+		DataSources: map[string]DataSource{
+			"config": {URL: mustURL("config.json")},
+			"inc":    {URL: mustURL("https://example.com/inc")},
+		},
+		Templates: map[string]DataSource{
+			"tpl": {URL: mustURL("tpl/")},
+		},
+		Plugins: map[string]PluginConfig{
+			"shout": {Cmd: "/bin/shout"},
+		},
+	}
+
+	issues, err := Lint(ctx, cfg)
+	require.NoError(t, err)
+
+	strs := make([]string, len(issues))
+	for i, issue := range issues {
+		strs[i] = issue.String()
+	}
+
+	assert.Equal(t, []string{
+		`in/bad.tmpl:1:4: error: unknown function "nope"`,
+		`in/bad.tmpl:2:6: error: unknown function "strings.Nope"`,
+		`in/bad.tmpl:2:28: error: undefined datasource "missing"`,
+		`in/bad.tmpl:3:13: error: undefined template "undefined"`,
+		`in/bad.tmpl:3:30: error: net.CIDRHost is experimental, and experimental mode is not enabled`,
+		`in/bad.tmpl:4:4: warning: crypto.EncryptAES is deprecated - use crypto.AESEncrypt instead`,
+		`in/broken.tmpl: error: template: in/broken.tmpl:1: missing value for if`,
+		`tpl/nested.tmpl:1:25: error: unknown function "bogus"`,
+	}, strs)
+
+	// nothing was written
+	_, err = hackpadfs.Stat(fsys, "out")
+	require.Error(t, err)
+
+	t.Run("experimental", func(t *testing.T) {
+		cfg := &Config{
+			Input:        `{{ net.CIDRHost 1 "10.0.0.0/8" }}`,
+			Experimental: true,
+		}
+
+		issues, err := Lint(ctx, cfg)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("remote nested templates", func(t *testing.T) {
+		// names of nested templates that aren't local can't be known
+		cfg := &Config{
+			Input: `{{ template "remote/foo.t" }}`,
+			Templates: map[string]DataSource{
+				"remote": {URL: mustURL("https://example.com/templates/")},
+			},
+		}
+
+		issues, err := Lint(ctx, cfg)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+}
+
+func TestHasMember(t *testing.T) {
+	funcs := CreateFuncs(context.Background())
+
+	assert.True(t, hasMember(funcs["strings"], "ToUpper"))
+	assert.False(t, hasMember(funcs["strings"], "Nope"))
+
+	// fields of namespaces can be used too
+	assert.True(t, hasMember(funcs["time"], "RFC3339"))
+
+	// functions which take arguments are never called
+	assert.True(t, hasMember(funcs["toUpper"], "Anything"))
+	assert.True(t, hasMember(nil, "Anything"))
+}
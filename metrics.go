@@ -1,6 +1,9 @@
 package gomplate
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // Metrics tracks interesting basic metrics around gomplate executions. Warning: experimental!
 // This may change in breaking ways without warning. This is not subject to any semantic versioning guarantees!
@@ -19,6 +22,15 @@ type MetricsType struct {
 	TemplatesGathered  int
 	TemplatesProcessed int
 	Errors             int
+
+	// datasource reads served from the cache (including reads coalesced with
+	// identical in-flight reads)
+	DatasourceCacheHits int
+	// datasource reads that weren't cached
+	DatasourceCacheMisses int
+
+	// guards fields updated while templates are rendered concurrently
+	mu sync.Mutex
 }
 
 func newMetrics() *MetricsType {
@@ -26,3 +38,39 @@ func newMetrics() *MetricsType {
 		RenderDuration: make(map[string]time.Duration),
 	}
 }
+
+// recordRender records the time taken to render the named template, and
+// whether it was rendered successfully. Safe for concurrent use.
+func (m *MetricsType) recordRender(name string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RenderDuration[name] = d
+	if err != nil {
+		m.Errors++
+		return
+	}
+	m.TemplatesProcessed++
+}
+
+// recordTotalRender records the time taken to render a set of templates. Safe
+// for concurrent use.
+func (m *MetricsType) recordTotalRender(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.TotalRenderDuration = d
+}
+
+// recordDatasourceCache records a datasource cache lookup. Safe for concurrent
+// use.
+func (m *MetricsType) recordDatasourceCache(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hit {
+		m.DatasourceCacheHits++
+		return
+	}
+	m.DatasourceCacheMisses++
+}
@@ -7,13 +7,13 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
 	"runtime"
 	"text/template"
 	"time"
 
 	"github.com/hairyhenderson/gomplate/v5/conv"
+	"github.com/hairyhenderson/gomplate/v5/internal/exechelpers"
 )
 
 // bindPlugins creates custom plugin functions for each plugin specified by
@@ -157,18 +157,7 @@ func (p *plugin) run(args ...any) (any, error) {
 	}
 
 	// make sure all signals are propagated
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs)
-	go func() {
-		select {
-		case sig := <-sigs:
-			// Pass signals to the sub-process
-			if c.Process != nil {
-				_ = c.Process.Signal(sig)
-			}
-		case <-ctx.Done():
-		}
-	}()
+	defer exechelpers.ForwardSignals(c.Process)()
 
 	err = c.Wait()
 	elapsed := time.Since(start)
@@ -1,12 +1,14 @@
 package gomplate
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"io/fs"
 	"maps"
 	"net/http"
+	"net/url"
 	"path"
 	"slices"
 	"strings"
@@ -16,6 +18,8 @@ import (
 	"github.com/hairyhenderson/go-fsimpl"
 	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
 	"github.com/hairyhenderson/gomplate/v5/internal/funcs"
+	"github.com/hairyhenderson/gomplate/v5/internal/parsers"
+	"golang.org/x/sync/errgroup"
 )
 
 // RenderOptions - options for controlling how templates are rendered, and
@@ -30,6 +34,11 @@ type RenderOptions struct {
 	// Templates - map of templates that can be referenced as nested templates
 	Templates map[string]DataSource
 
+	// DatasourceOverrides - replacement URLs for datasources (including
+	// contexts), by alias. Only the URL is replaced. Overrides also apply to
+	// datasources defined at runtime, with the 'defineDatasource' function.
+	DatasourceOverrides map[string]*url.URL
+
 	// Extra HTTP headers not attached to pre-defined datsources. Potentially
 	// used by datasources defined in the template.
 	ExtraHeaders map[string]http.Header
@@ -47,6 +56,34 @@ type RenderOptions struct {
 
 	// MissingKey controls the behavior during execution if a map is indexed with a key that is not present in the map
 	MissingKey string
+
+	// Parallelism - the maximum number of templates to render concurrently
+	// when [Renderer.RenderTemplates] is given more than one template. Values
+	// below 2 render templates one at a time. Templates rendered concurrently
+	// must not share a Writer.
+	Parallelism int
+
+	// CacheDir - the directory for the persistent cache of remote datasources
+	// (those with a CacheTTL). Defaults to a "gomplate" directory in the
+	// user's cache directory.
+	CacheDir string
+	// Offline - read remote datasources only from the persistent cache
+	Offline bool
+
+	// Stderr - where commands run by exec datasources write their standard
+	// error. Defaults to os.Stderr.
+	Stderr io.Writer
+
+	// Parsers - custom data formats, by MIME type. Datasources (including
+	// context and merged datasources) of these types are parsed with the
+	// Parser's Parse function, and files with the Parser's extensions are
+	// detected as that type. Built-in types can be overridden.
+	Parsers map[string]Parser
+
+	// datasource reads are recorded into the fixture, or replayed from it
+	// when replay is set
+	fixture *datafs.Fixture
+	replay  bool
 }
 
 // optionsFromConfig - translate the internal config struct to a RenderOptions.
@@ -60,19 +97,30 @@ func optionsFromConfig(cfg *Config) RenderOptions {
 		LDelim:       cfg.LDelim,
 		RDelim:       cfg.RDelim,
 		MissingKey:   cfg.MissingKey,
+		Parallelism:  cfg.Parallelism,
+		CacheDir:     cfg.CacheDir,
+		Offline:      cfg.Offline,
+		Stderr:       cfg.Stderr,
 	}
 
 	return opts
 }
 
+// Parser - a parse function, and optionally an encoder, for a custom data
+// format. See [RenderOptions.Parsers].
+type Parser = parsers.Parser
+
 type renderer struct {
 	sr          datafs.DataSourceReader
+	parsers     *parsers.Registry
 	nested      map[string]DataSource
 	funcs       template.FuncMap
 	lDelim      string
 	rDelim      string
 	missingKey  string
 	tctxAliases []string
+	parallelism int
+	stderr      io.Writer
 }
 
 // Renderer provides gomplate's core template rendering functionality.
@@ -91,8 +139,7 @@ type Renderer interface {
 }
 
 // NewRenderer creates a new template renderer with the specified options.
-// The returned renderer can be reused, but it is not (yet) safe for concurrent
-// use.
+// The returned renderer can be reused, and is safe for concurrent use.
 //
 // Experimental: subject to breaking changes before the next major release
 func NewRenderer(opts RenderOptions) Renderer {
@@ -107,19 +154,37 @@ func newRenderer(opts RenderOptions) *renderer {
 	// this should be the only place where this registry is created
 	reg := datafs.NewRegistry()
 
+	for alias, u := range opts.DatasourceOverrides {
+		reg.AddURLOverride(alias, u)
+	}
+
 	tctxAliases := make([]string, 0, len(opts.Context))
 
 	for alias, ds := range opts.Context {
 		tctxAliases = append(tctxAliases, alias)
 		reg.Register(alias, DataSource{
-			URL:    ds.URL,
-			Header: ds.Header,
+			URL:      ds.URL,
+			Header:   ds.Header,
+			CacheTTL: ds.CacheTTL,
+			Retries:  ds.Retries,
+			Timeout:  ds.Timeout,
+			Backoff:  ds.Backoff,
+			TLS:      ds.TLS,
+			Method:   ds.Method,
+			Body:     ds.Body,
 		})
 	}
 	for alias, ds := range opts.Datasources {
 		reg.Register(alias, DataSource{
-			URL:    ds.URL,
-			Header: ds.Header,
+			URL:      ds.URL,
+			Header:   ds.Header,
+			CacheTTL: ds.CacheTTL,
+			Retries:  ds.Retries,
+			Timeout:  ds.Timeout,
+			Backoff:  ds.Backoff,
+			TLS:      ds.TLS,
+			Method:   ds.Method,
+			Body:     ds.Body,
 		})
 	}
 
@@ -146,17 +211,40 @@ func newRenderer(opts RenderOptions) *renderer {
 		missingKey = "error"
 	}
 
-	sr := datafs.NewSourceReader(reg)
+	sr := datafs.NewSourceReaderWithOptions(reg, datafs.SourceReaderOptions{
+		// Metrics is read at lookup time, since it may be reset between runs
+		CacheObserver: func(hit bool) {
+			Metrics.recordDatasourceCache(hit)
+		},
+		CacheDir: opts.CacheDir,
+		Offline:  opts.Offline,
+		Fixture:  opts.fixture,
+		Replay:   opts.replay,
+	})
 
 	return &renderer{
 		nested:      opts.Templates,
 		sr:          sr,
+		parsers:     parsers.NewRegistry(opts.Parsers),
 		funcs:       opts.Funcs,
 		tctxAliases: tctxAliases,
 		lDelim:      opts.LDelim,
 		rDelim:      opts.RDelim,
 		missingKey:  missingKey,
+		parallelism: opts.Parallelism,
+		stderr:      opts.Stderr,
+	}
+}
+
+// withOptions adds the renderer's custom parsers to the context, so that they
+// are used wherever datasources are parsed, as well as the stderr for commands
+// run by exec datasources
+func (r *renderer) withOptions(ctx context.Context) context.Context {
+	if r.stderr != nil {
+		ctx = datafs.ContextWithStderr(ctx, r.stderr)
 	}
+
+	return parsers.ContextWithRegistry(ctx, r.parsers)
 }
 
 // Template contains the basic data needed to render a template with a Renderer
@@ -178,7 +266,7 @@ func (r *renderer) RenderTemplates(ctx context.Context, templates []Template) er
 
 	// configure the template context with the refreshed Data value
 	// only done here because the data context may have changed
-	tmplctx, err := createTmplContext(ctx, r.tctxAliases, r.sr)
+	tmplctx, err := createTmplContext(r.withOptions(ctx), r.tctxAliases, r.sr)
 	if err != nil {
 		return err
 	}
@@ -187,19 +275,26 @@ func (r *renderer) RenderTemplates(ctx context.Context, templates []Template) er
 }
 
 func (r *renderer) renderTemplatesWithData(ctx context.Context, templates []Template, tmplctx any) error {
+	ctx = r.withOptions(ctx)
+
 	// update funcs with the current context
 	// only done here to ensure the context is properly set in func namespaces
 	f := CreateFuncs(ctx)
 
 	// add datasource funcs here because they need to share the source reader
-	maps.Copy(f, funcs.CreateDataSourceFuncs(ctx, r.sr))
+	maps.Copy(f, funcs.CreateDataSourceFuncs(ctx, withDepsReader(ctx, r.sr)))
 
 	// add user-defined funcs last so they override the built-in funcs
 	maps.Copy(f, r.funcs)
 
 	// track some metrics for debug output
 	start := time.Now()
-	defer func() { Metrics.TotalRenderDuration = time.Since(start) }()
+	defer func() { Metrics.recordTotalRender(time.Since(start)) }()
+
+	if r.parallelism > 1 && len(templates) > 1 {
+		return r.renderTemplatesParallel(ctx, templates, f, tmplctx)
+	}
+
 	for _, template := range templates {
 		err := r.renderTemplate(ctx, template, f, tmplctx)
 		if err != nil {
@@ -209,6 +304,79 @@ func (r *renderer) renderTemplatesWithData(ctx context.Context, templates []Temp
 	return nil
 }
 
+// renderTemplatesParallel renders the templates on a bounded pool of
+// goroutines. Once a template fails to render, no more templates are started,
+// and the first error is returned.
+//
+// Each template's output is buffered, and written out in input order once all
+// templates are rendered, so that templates sharing an output (like stdout)
+// aren't interleaved.
+func (r *renderer) renderTemplatesParallel(ctx context.Context, templates []Template, f template.FuncMap, tmplctx any) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(r.parallelism)
+
+	bufs := make([]*bytes.Buffer, len(templates))
+
+	for i, template := range templates {
+		// stop starting new templates once one has failed
+		if gctx.Err() != nil {
+			break
+		}
+
+		g.Go(func() error {
+			buf := &bytes.Buffer{}
+			template.Writer = buf
+
+			err := r.renderTemplate(ctx, template, f, tmplctx)
+			if err != nil {
+				return fmt.Errorf("renderTemplate: %w", err)
+			}
+
+			bufs[i] = buf
+
+			return nil
+		})
+	}
+
+	err := g.Wait()
+
+	// templates that rendered successfully are written even when others
+	// failed, as when rendering one at a time. Failed templates have no
+	// output, but their writers still need to be closed.
+	for i, buf := range bufs {
+		var b []byte
+		if buf != nil {
+			b = buf.Bytes()
+		}
+
+		werr := writeOutput(templates[i].Writer, b)
+		if werr != nil && err == nil {
+			err = fmt.Errorf("write output for %s: %w", templates[i].Name, werr)
+		}
+	}
+
+	return err
+}
+
+// writeOutput writes b to wr, closing wr afterwards if it's a Closer
+func writeOutput(wr io.Writer, b []byte) error {
+	if wr == nil {
+		return nil
+	}
+
+	if c, ok := wr.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	_, err := wr.Write(b)
+
+	return err
+}
+
 func (r *renderer) renderTemplate(ctx context.Context, template Template, f template.FuncMap, tmplctx any) error {
 	if template.Writer != nil {
 		if wr, ok := template.Writer.(io.Closer); ok {
@@ -222,13 +390,13 @@ func (r *renderer) renderTemplate(ctx context.Context, template Template, f temp
 		return fmt.Errorf("parse template %s: %w", template.Name, err)
 	}
 
+	recordTemplateDeps(ctx, tmpl)
+
 	err = tmpl.Execute(template.Writer, tmplctx)
-	Metrics.RenderDuration[template.Name] = time.Since(tstart)
+	Metrics.recordRender(template.Name, time.Since(tstart), err)
 	if err != nil {
-		Metrics.Errors++
 		return fmt.Errorf("failed to render template %s: %w", template.Name, err)
 	}
-	Metrics.TemplatesProcessed++
 
 	return nil
 }
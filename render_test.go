@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/hairyhenderson/go-fsimpl"
 	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
@@ -82,6 +88,232 @@ func TestRenderTemplate(t *testing.T) {
 	assert.ErrorContains(t, err, "template: foo:")
 }
 
+func TestRenderTemplatesParallel(t *testing.T) {
+	srv := fakeJSONServer()
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+	tr := NewRenderer(RenderOptions{
+		Datasources: map[string]DataSource{
+			"pet": {URL: u},
+		},
+		Parallelism: 4,
+	})
+
+	templates := make([]Template, 20)
+	for i := range templates {
+		templates[i] = Template{
+			Name:   fmt.Sprintf("t%d", i),
+			Text:   fmt.Sprintf(`{{ (ds "pet").name }} %d`, i),
+			Writer: &bytes.Buffer{},
+		}
+	}
+
+	ctx := t.Context()
+	err := tr.RenderTemplates(ctx, templates)
+	require.NoError(t, err)
+
+	for i, tmpl := range templates {
+		assert.Equal(t, fmt.Sprintf("Luna %d", i), tmpl.Writer.(*bytes.Buffer).String())
+	}
+
+	// the renderer itself can also be used concurrently
+	errs := make(chan error, len(templates))
+	for range templates {
+		go func() {
+			errs <- tr.Render(ctx, "concurrent", `{{ (ds "pet").breed }}`, &bytes.Buffer{})
+		}()
+	}
+	for range templates {
+		require.NoError(t, <-errs)
+	}
+
+	// the first error is returned
+	templates[3].Text = `{{ bogus }}`
+	err = tr.RenderTemplates(ctx, templates)
+	require.ErrorContains(t, err, "t3")
+}
+
+// syncWriter is an io.Writer that can be written to concurrently
+type syncWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func TestRenderTemplatesParallel_SharedWriter(t *testing.T) {
+	tr := NewRenderer(RenderOptions{
+		Parallelism: 4,
+		Funcs: map[string]any{
+			// later templates finish first
+			"wait": func(i int) string {
+				time.Sleep(time.Duration(10-i) * 5 * time.Millisecond)
+				return ""
+			},
+		},
+	})
+
+	out := &syncWriter{}
+
+	templates := make([]Template, 10)
+	expected := ""
+	for i := range templates {
+		templates[i] = Template{
+			Name: fmt.Sprintf("t%d", i),
+			// written in several parts, to catch interleaving
+			Text:   fmt.Sprintf(`start %d{{ wait %d }}`+"\n"+`{{ wait %d }}end %d`+"\n", i, i, i, i),
+			Writer: out,
+		}
+		expected += fmt.Sprintf("start %d\nend %d\n", i, i)
+	}
+
+	err := tr.RenderTemplates(t.Context(), templates)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out.buf.String())
+}
+
+func TestRenderDatasourceCache(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"Luna"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	Metrics = newMetrics()
+
+	u, _ := url.Parse(srv.URL)
+	tr := NewRenderer(RenderOptions{
+		Datasources: map[string]DataSource{
+			"pet": {URL: u},
+		},
+	})
+
+	// concurrent reads of the same datasource result in a single request
+	ctx := t.Context()
+	errs := make(chan error, 10)
+	for range 10 {
+		go func() {
+			errs <- tr.Render(ctx, "t", `{{ (ds "pet").name }}`, &bytes.Buffer{})
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		Metrics.mu.Lock()
+		defer Metrics.mu.Unlock()
+		return Metrics.DatasourceCacheHits+Metrics.DatasourceCacheMisses == 10
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+
+	for range 10 {
+		require.NoError(t, <-errs)
+	}
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, 9, Metrics.DatasourceCacheHits)
+	assert.Equal(t, 1, Metrics.DatasourceCacheMisses)
+}
+
+func TestRenderCustomParsers(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.kv":     "name=Luna\nkind=cat",
+		"b.kv":     "kind=dog\nage=3",
+		"c.txt":    "colour=grey",
+		"d.json":   `{"name":"Ziggy"}`,
+		"merge.kv": "owner=Jo",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	ctx := t.Context()
+
+	kv := Parser{
+		Parse: func(in []byte) (any, error) {
+			out := map[string]any{}
+			for line := range strings.Lines(string(in)) {
+				k, v, _ := strings.Cut(strings.TrimSpace(line), "=")
+				out[k] = v
+			}
+			return out, nil
+		},
+		Encode: func(in any) ([]byte, error) {
+			m, ok := in.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected a map, got %T", in)
+			}
+			out := []string{}
+			for _, k := range slices.Sorted(maps.Keys(m)) {
+				out = append(out, fmt.Sprintf("%s=%v", k, m[k]))
+			}
+			return []byte(strings.Join(out, "\n")), nil
+		},
+		Extensions: []string{".kv"},
+	}
+
+	mu := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		return u
+	}
+	base := "file://" + filepath.ToSlash(dir) + "/"
+
+	tr := NewRenderer(RenderOptions{
+		Context: map[string]DataSource{
+			"a": {URL: mu(base + "a.kv")},
+		},
+		Datasources: map[string]DataSource{
+			// detected by extension
+			"b": {URL: mu(base + "b.kv")},
+			// type given explicitly
+			"c": {URL: mu(base + "c.txt?type=application/x-kv")},
+			// overriding a built-in type
+			"d": {URL: mu(base + "d.json")},
+			"m": {URL: mu("merge:" + base + "merge.kv|" + base + "b.kv")},
+		},
+		Parsers: map[string]Parser{
+			"application/x-kv": kv,
+			"application/json": {
+				Parse: func(in []byte) (any, error) {
+					return map[string]any{"name": "overridden"}, nil
+				},
+			},
+		},
+	})
+
+	out := &bytes.Buffer{}
+	err := tr.Render(ctx, "test",
+		`{{ .a.name }} {{ (ds "b").kind }} {{ (ds "c").colour }} {{ (ds "d").name }} {{ ds "m" | data.Encode "application/x-kv" }}`,
+		out)
+	require.NoError(t, err)
+	assert.Equal(t, "Luna dog grey overridden age=3\nkind=dog\nowner=Jo", out.String())
+
+	// built-in encoders are still available
+	out = &bytes.Buffer{}
+	err = tr.Render(ctx, "test", `{{ data.Parse "application/x-kv" "a=1" | data.Encode "application/yaml" }}`, out)
+	require.NoError(t, err)
+	assert.Equal(t, "a: \"1\"\n", out.String())
+
+	// custom parsers aren't used without the option
+	tr = NewRenderer(RenderOptions{
+		Datasources: map[string]DataSource{
+			"b": {URL: mu(base + "b.kv")},
+		},
+	})
+	err = tr.Render(ctx, "test", `{{ (ds "b").kind }}`, &bytes.Buffer{})
+	require.Error(t, err)
+}
+
 //// examples
 
 func ExampleRenderer() {
@@ -61,6 +61,8 @@ func gatherTemplates(ctx context.Context, cfg *Config, outFileNamer outputNamer)
 			return nil, fmt.Errorf("openOutFile: %w", oerr)
 		}
 
+		labelOutput(ctx, cfg.OutputFiles[0], "<arg>")
+
 		templates = []Template{{
 			// the arg-provided input string gets a special name
 			Name:   "<arg>",
@@ -174,6 +176,13 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 			return nil, fmt.Errorf("fileToTemplate: %w", err)
 		}
 
+		// nothing is written when output is only being compared (e.g. in
+		// check and diff modes), so no dirs are needed
+		if driftReportFromContext(ctx) != nil {
+			templates = append(templates, tpl)
+			continue
+		}
+
 		// Ensure file parent dirs - use separate fsys for output file
 		outfsys, err := datafs.FSysForPath(ctx, outFile)
 		if err != nil {
@@ -251,6 +260,7 @@ func copyFileToOutDir(ctx context.Context, cfg *Config, inFile, outFile string,
 	if err != nil {
 		return err
 	}
+	labelOutput(ctx, outFile, inFile)
 
 	wr, ok := outFH.(io.Closer)
 	if ok && wr != os.Stdout {
@@ -271,6 +281,7 @@ func fileToTemplate(ctx context.Context, cfg *Config, inFile, outFile string, mo
 	if err != nil {
 		return Template{}, err
 	}
+	labelOutput(ctx, outFile, inFile)
 
 	tmpl := Template{
 		Name:   inFile,
@@ -290,6 +301,11 @@ func fileToTemplate(ctx context.Context, cfg *Config, inFile, outFile string, mo
 //
 //nolint:unparam
 func openOutFile(ctx context.Context, filename string, dirMode, mode os.FileMode, modeOverride bool, stdout io.Writer) (out io.Writer, err error) {
+	// in check and diff modes, output is only compared with the existing file
+	if drift := driftReportFromContext(ctx); drift != nil {
+		return drift.writer(ctx, filename), nil
+	}
+
 	out = iohelpers.NewEmptySkipper(func() (io.Writer, error) {
 		if filename == "-" {
 			return iohelpers.NopCloser(stdout), nil
@@ -0,0 +1,450 @@
+package gomplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+)
+
+// watchPollInterval is how often watched files are checked for changes
+//
+//nolint:gochecknoglobals
+var watchPollInterval = 500 * time.Millisecond
+
+// watchSignals are the signals that stop watching
+//
+//nolint:gochecknoglobals
+var watchSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// templateDeps records the datasources and nested templates referenced while
+// rendering a single template
+type templateDeps struct {
+	datasources map[string]struct{}
+	templates   map[string]struct{}
+}
+
+func newTemplateDeps() *templateDeps {
+	return &templateDeps{
+		datasources: map[string]struct{}{},
+		templates:   map[string]struct{}{},
+	}
+}
+
+type depsCtxKey struct{}
+
+func contextWithDeps(ctx context.Context, deps *templateDeps) context.Context {
+	return context.WithValue(ctx, depsCtxKey{}, deps)
+}
+
+func depsFromContext(ctx context.Context) *templateDeps {
+	deps, _ := ctx.Value(depsCtxKey{}).(*templateDeps)
+	return deps
+}
+
+// depsReader is a DataSourceReader that records the aliases of all
+// datasources read through it
+type depsReader struct {
+	datafs.DataSourceReader
+	deps *templateDeps
+}
+
+func (r *depsReader) ReadSource(ctx context.Context, alias string, args ...string) (string, []byte, error) {
+	r.deps.datasources[alias] = struct{}{}
+	return r.DataSourceReader.ReadSource(ctx, alias, args...)
+}
+
+// withDepsReader wraps sr so that datasource reads are recorded, if the
+// context is tracking dependencies
+func withDepsReader(ctx context.Context, sr datafs.DataSourceReader) datafs.DataSourceReader {
+	deps := depsFromContext(ctx)
+	if deps == nil {
+		return sr
+	}
+
+	return &depsReader{DataSourceReader: sr, deps: deps}
+}
+
+// recordTemplateDeps records the names of all templates reachable from the
+// root template tmpl, if the context is tracking dependencies
+func recordTemplateDeps(ctx context.Context, tmpl *template.Template) {
+	deps := depsFromContext(ctx)
+	if deps == nil || tmpl.Tree == nil {
+		return
+	}
+
+	queue := []parse.Node{tmpl.Tree.Root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, name := range templateRefs(node) {
+			if _, ok := deps.templates[name]; ok {
+				continue
+			}
+			deps.templates[name] = struct{}{}
+
+			if t := tmpl.Lookup(name); t != nil && t.Tree != nil {
+				queue = append(queue, t.Tree.Root)
+			}
+		}
+	}
+}
+
+// templateRefs returns the names of the templates invoked by the given node
+// and its children
+func templateRefs(node parse.Node) []string {
+	refs := []string{}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return refs
+		}
+		for _, c := range n.Nodes {
+			refs = append(refs, templateRefs(c)...)
+		}
+	case *parse.TemplateNode:
+		refs = append(refs, n.Name)
+	case *parse.IfNode:
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	case *parse.RangeNode:
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	case *parse.WithNode:
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	}
+
+	return refs
+}
+
+type watchKind int
+
+const (
+	watchInput watchKind = iota
+	watchContext
+	watchDatasource
+	watchNested
+)
+
+// watchRoot is a file or directory being watched for changes
+type watchRoot struct {
+	path  string
+	alias string
+	kind  watchKind
+}
+
+// contains reports whether the file at p is (or is inside) this root
+func (r watchRoot) contains(p string) bool {
+	return p == r.path || strings.HasPrefix(p, r.path+string(filepath.Separator))
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher renders templates, and then re-renders those affected by changes to
+// input files, nested templates, and file-backed datasources
+type watcher struct {
+	cfg  *Config
+	opts RenderOptions
+
+	// dependencies of each template, as of its last render
+	deps map[string]*templateDeps
+	// all datasources registered as of the last render, including those
+	// defined in templates
+	sources map[string]*url.URL
+	// state of each watched file, as of the last scan
+	files map[string]fileStamp
+}
+
+// watch renders all templates, then watches for changes until the context is
+// cancelled
+func watch(ctx context.Context, cfg *Config, opts RenderOptions) error {
+	// signals stop the watch loop, but only between renders, so that outputs
+	// aren't left half-written. Renders use the parent context, and a second
+	// signal has the default behaviour, in case a render is stuck.
+	sigCtx, stop := signal.NotifyContext(ctx, watchSignals...)
+	defer stop()
+	context.AfterFunc(sigCtx, stop)
+
+	w := &watcher{
+		cfg:     cfg,
+		opts:    opts,
+		deps:    map[string]*templateDeps{},
+		sources: map[string]*url.URL{},
+	}
+
+	err := w.render(ctx, func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	w.files = w.scan()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sigCtx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		files := w.scan()
+		changed := changedFiles(w.files, files)
+		w.files = files
+
+		if len(changed) == 0 {
+			continue
+		}
+
+		slog.DebugContext(ctx, "watched files changed", "files", changed)
+
+		affected := w.affectedBy(changed)
+
+		err = w.render(ctx, affected)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to re-render templates", "err", err)
+		}
+	}
+}
+
+// render gathers all templates and renders those selected by the filter
+// function, recording their dependencies. Individual render failures are logged
+// rather than returned, so that watching can continue.
+func (w *watcher) render(ctx context.Context, selected func(name string) bool) error {
+	// a fresh renderer is needed so that no stale datasource content is cached
+	tr := newRenderer(w.opts)
+	namer := chooseNamer(w.cfg, tr)
+
+	start := time.Now()
+	tmpls, err := gatherTemplates(ctx, w.cfg, namer)
+	Metrics.GatherDuration = time.Since(start)
+	if err != nil {
+		Metrics.Errors++
+		return fmt.Errorf("failed to gather templates for rendering: %w", err)
+	}
+	Metrics.TemplatesGathered = len(tmpls)
+
+	tctx, err := createTmplContext(tr.withOptions(ctx), tr.tctxAliases, tr.sr)
+	if err != nil {
+		return err
+	}
+
+	rendered := 0
+	for _, t := range tmpls {
+		if !selected(t.Name) {
+			continue
+		}
+
+		deps := newTemplateDeps()
+		err = tr.renderTemplatesWithData(contextWithDeps(ctx, deps), []Template{t}, tctx)
+		w.deps[t.Name] = deps
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to render template", "template", t.Name, "err", err)
+			continue
+		}
+		rendered++
+	}
+
+	for _, alias := range tr.sr.List() {
+		if ds, ok := tr.sr.Lookup(alias); ok && ds.URL != nil {
+			w.sources[alias] = ds.URL
+		}
+	}
+
+	slog.DebugContext(ctx, "rendered templates", "count", rendered)
+
+	return nil
+}
+
+// roots returns the files and directories to watch
+func (w *watcher) roots() []watchRoot {
+	roots := []watchRoot{}
+
+	if w.cfg.InputDir != "" {
+		roots = append(roots, watchRoot{path: filepath.Clean(w.cfg.InputDir), kind: watchInput})
+	}
+	for _, f := range w.cfg.InputFiles {
+		if f != "-" {
+			roots = append(roots, watchRoot{path: f, kind: watchInput})
+		}
+	}
+
+	for alias, ds := range w.cfg.Templates {
+		if p, ok := localPath(ds.URL); ok {
+			roots = append(roots, watchRoot{path: p, alias: alias, kind: watchNested})
+		}
+	}
+
+	for alias, u := range w.sources {
+		p, ok := localPath(u)
+		if !ok {
+			continue
+		}
+
+		kind := watchDatasource
+		if _, ok := w.cfg.Context[alias]; ok {
+			kind = watchContext
+		}
+
+		roots = append(roots, watchRoot{path: p, alias: alias, kind: kind})
+	}
+
+	return roots
+}
+
+// localPath returns the local filesystem path referenced by the URL, if it's a
+// file URL
+func localPath(u *url.URL) (string, bool) {
+	if u == nil || u.Opaque != "" || u.Path == "" {
+		return "", false
+	}
+	if u.Scheme != "" && u.Scheme != "file" {
+		return "", false
+	}
+
+	return filepath.Clean(filepath.FromSlash(u.Path)), true
+}
+
+// scan records the current state of all watched files
+func (w *watcher) scan() map[string]fileStamp {
+	files := map[string]fileStamp{}
+
+	for _, root := range w.roots() {
+		// errors are ignored - missing files are simply not recorded, and will
+		// be seen as changed if they appear later
+		_ = filepath.WalkDir(root.path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+
+			fi, err := d.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+
+			files[p] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+
+			return nil
+		})
+	}
+
+	return files
+}
+
+// changedFiles returns the paths of all files that were added, removed, or
+// modified between the two scans
+func changedFiles(before, after map[string]fileStamp) []string {
+	changed := []string{}
+
+	for p, s := range after {
+		if b, ok := before[p]; !ok || !b.modTime.Equal(s.modTime) || b.size != s.size {
+			changed = append(changed, p)
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+// affectedBy returns a filter function selecting the templates affected by
+// changes to the given files
+func (w *watcher) affectedBy(changed []string) func(name string) bool {
+	inputs := map[string]bool{}
+	aliases := map[string]bool{}
+	nested := []string{}
+	all := false
+
+	roots := w.roots()
+	for _, p := range changed {
+		for _, root := range roots {
+			if !root.contains(p) {
+				continue
+			}
+
+			switch root.kind {
+			case watchInput:
+				if p == root.path {
+					inputs[root.path] = true
+				} else {
+					inputs[filepath.ToSlash(p)] = true
+				}
+			case watchContext:
+				// the context is shared by all templates
+				all = true
+			case watchDatasource:
+				aliases[root.alias] = true
+			case watchNested:
+				nested = append(nested, root.alias)
+			}
+		}
+	}
+
+	// merged datasources are affected when any of their parts are
+	for alias, u := range w.sources {
+		if u.Scheme != "merge" {
+			continue
+		}
+		for part := range strings.SplitSeq(u.Opaque, "|") {
+			if aliases[part] {
+				aliases[alias] = true
+			}
+		}
+	}
+
+	return func(name string) bool {
+		if all || inputs[name] {
+			return true
+		}
+
+		deps, ok := w.deps[name]
+		if !ok {
+			// never rendered, so it must be new
+			return true
+		}
+
+		for alias := range deps.datasources {
+			if aliases[alias] {
+				return true
+			}
+		}
+
+		for t := range deps.templates {
+			for _, n := range nested {
+				if t == n || strings.HasPrefix(t, n+"/") {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
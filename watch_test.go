@@ -0,0 +1,153 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v5/internal/datafs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordTemplateDeps(t *testing.T) {
+	fsys := fstest.MapFS{
+		"nested/a.t": &fstest.MapFile{Data: []byte(`{{ template "nested/b.t" . }}`)},
+		"nested/b.t": &fstest.MapFile{Data: []byte(`b`)},
+		"other.t":    &fstest.MapFile{Data: []byte(`other`)},
+	}
+	fsp := fsimpl.NewMux()
+	fsp.Add(datafs.EnvFS)
+	fsp.Add(datafs.WrappedFSProvider(fsys, "file", ""))
+	ctx := datafs.ContextWithFSProvider(context.Background(), fsp)
+
+	t.Setenv("FOO", "foo")
+
+	tr := newRenderer(RenderOptions{
+		Datasources: map[string]DataSource{
+			"foo": {URL: &url.URL{Scheme: "env", Opaque: "FOO"}},
+		},
+		Templates: map[string]DataSource{
+			"nested": {URL: &url.URL{Scheme: "file", Path: "/nested/"}},
+			"other":  {URL: &url.URL{Scheme: "file", Path: "/other.t"}},
+		},
+	})
+
+	deps := newTemplateDeps()
+	out := &bytes.Buffer{}
+	err := tr.renderTemplatesWithData(contextWithDeps(ctx, deps), []Template{{
+		Name:   "root",
+		Text:   `{{ define "local" }}{{ template "nested/a.t" }}{{ end }}{{ if true }}{{ template "local" }}{{ end }}{{ include "foo" }}`,
+		Writer: out,
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bfoo", out.String())
+
+	assert.Equal(t, map[string]struct{}{"foo": {}}, deps.datasources)
+	assert.Equal(t, map[string]struct{}{
+		"local": {}, "nested/a.t": {}, "nested/b.t": {},
+	}, deps.templates)
+}
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	before := map[string]fileStamp{
+		"same":    {modTime: now, size: 1},
+		"touched": {modTime: now, size: 1},
+		"grown":   {modTime: now, size: 1},
+		"removed": {modTime: now, size: 1},
+	}
+	after := map[string]fileStamp{
+		"same":    {modTime: now, size: 1},
+		"touched": {modTime: now.Add(time.Second), size: 1},
+		"grown":   {modTime: now, size: 2},
+		"added":   {modTime: now, size: 1},
+	}
+
+	assert.Equal(t, []string{"added", "grown", "removed", "touched"}, changedFiles(before, after))
+	assert.Empty(t, changedFiles(after, after))
+}
+
+func TestLocalPath(t *testing.T) {
+	p, ok := localPath(&url.URL{Scheme: "file", Path: "/tmp/foo.json"})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.FromSlash("/tmp/foo.json"), p)
+
+	p, ok = localPath(&url.URL{Path: "foo/bar.json"})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.FromSlash("foo/bar.json"), p)
+
+	_, ok = localPath(&url.URL{Scheme: "env", Opaque: "FOO"})
+	assert.False(t, ok)
+
+	_, ok = localPath(&url.URL{Scheme: "https", Host: "example.com", Path: "/foo.json"})
+	assert.False(t, ok)
+
+	_, ok = localPath(nil)
+	assert.False(t, ok)
+}
+
+func TestWatch(t *testing.T) {
+	orig := watchPollInterval
+	watchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { watchPollInterval = orig })
+
+	dir := t.TempDir()
+	inDir := filepath.Join(dir, "in")
+	outDir := filepath.Join(dir, "out")
+	dataFile := filepath.Join(dir, "data.json")
+
+	require.NoError(t, os.MkdirAll(inDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(inDir, "a.txt"), []byte(`{{ (ds "data").value }}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(inDir, "b.txt"), []byte(`static`), 0o644))
+	require.NoError(t, os.WriteFile(dataFile, []byte(`{"value": "one"}`), 0o644))
+
+	cfg := &Config{
+		InputDir:  inDir,
+		OutputDir: outDir,
+		DataSources: map[string]DataSource{
+			"data": {URL: &url.URL{Scheme: "file", Path: filepath.ToSlash(dataFile)}},
+		},
+		Watch: true,
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- Run(ctx, cfg)
+	}()
+
+	readOut := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(outDir, name))
+		return string(b)
+	}
+
+	require.Eventually(t, func() bool {
+		return readOut("a.txt") == "one" && readOut("b.txt") == "static"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// b.txt doesn't depend on the datasource, so it shouldn't be re-rendered
+	require.NoError(t, os.Remove(filepath.Join(outDir, "b.txt")))
+
+	require.NoError(t, os.WriteFile(dataFile, []byte(`{"value": "three"}`), 0o644))
+	require.Eventually(t, func() bool {
+		return readOut("a.txt") == "three"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(outDir, "b.txt"))
+
+	// new templates are rendered as they appear
+	require.NoError(t, os.WriteFile(filepath.Join(inDir, "c.txt"), []byte(`{{ "c" | toUpper }}`), 0o644))
+	require.Eventually(t, func() bool {
+		return readOut("c.txt") == "C"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(outDir, "b.txt"))
+
+	cancel()
+	require.NoError(t, <-errs)
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package gomplate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch_Signal(t *testing.T) {
+	origInterval, origSignals := watchPollInterval, watchSignals
+	watchPollInterval = 10 * time.Millisecond
+	// SIGUSR1 is used so that a failure to handle the signal doesn't
+	// interrupt the whole test run
+	watchSignals = []os.Signal{syscall.SIGUSR1}
+	t.Cleanup(func() { watchPollInterval, watchSignals = origInterval, origSignals })
+
+	dir := t.TempDir()
+	inDir := filepath.Join(dir, "in")
+	outDir := filepath.Join(dir, "out")
+
+	require.NoError(t, os.MkdirAll(inDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(inDir, "a.txt"), []byte(`hello`), 0o644))
+
+	cfg := &Config{InputDir: inDir, OutputDir: outDir, Watch: true}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- Run(context.Background(), cfg)
+	}()
+
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(filepath.Join(outDir, "a.txt"))
+		return string(b) == "hello"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "watch didn't stop after signal")
+	}
+}